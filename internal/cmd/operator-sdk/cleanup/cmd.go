@@ -36,7 +36,6 @@ func NewCmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			u.Package = args[0]
 			u.DeleteOperatorGroupNames = []string{operator.SDKOperatorGroupName}
-			u.Logf = log.Infof
 
 			ctx, cancel := context.WithTimeout(cmd.Context(), cfg.Timeout)
 			defer cancel()
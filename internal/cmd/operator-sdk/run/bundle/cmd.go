@@ -16,6 +16,7 @@ package bundle
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -26,6 +27,7 @@ import (
 
 func NewCmd(cfg *operator.Configuration) *cobra.Command {
 	i := bundle.NewInstall(cfg)
+	var quiet bool
 	cmd := &cobra.Command{
 		Use:   "bundle <bundle-image>",
 		Short: "Deploy an Operator in the bundle format with OLM",
@@ -38,24 +40,55 @@ The ` + "`--index-image`" + ` flag specifies an index image in which to inject t
 This is an optional flag which will default to ` + "`quay.io/operator-framework/opm:latest`." + `
 The index image provided should **NOT** already have the bundle.
 `,
-		Args:    cobra.ExactArgs(1),
-		PreRunE: func(*cobra.Command, []string) error { return cfg.Load() },
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(*cobra.Command, []string) error {
+			if quiet {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+			// Validating a bundle does not use the cluster, so do not require access to one.
+			if i.ValidateOnly {
+				return nil
+			}
+			return cfg.Load()
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx, cancel := context.WithTimeout(cmd.Context(), cfg.Timeout)
 			defer cancel()
 
 			i.BundleImage = args[0]
 
+			if i.ValidateOnly {
+				if err := i.ValidateBundleImage(ctx); err != nil {
+					logrus.Fatalf("Failed to validate bundle: %v\n", err)
+				}
+				return
+			}
+
+			shutdownTracing, err := operator.SetupTracing(ctx)
+			if err != nil {
+				logrus.Fatalf("Failed to set up tracing: %v\n", err)
+			}
+
 			// TODO(joelanford): Add cleanup logic if this fails?
-			_, err := i.Run(ctx)
+			csv, err := i.Run(ctx)
+			// Flush traces before a failure exits.
+			if err := shutdownTracing(context.Background()); err != nil {
+				logrus.Warnf("Failed to export traces: %v", err)
+			}
 			if err != nil {
 				logrus.Fatalf("Failed to run bundle: %v\n", err)
 			}
+			// Info logs, including the success message, are suppressed, so print the result.
+			if quiet && csv != nil {
+				fmt.Println(csv.GetName())
+			}
 		},
 	}
 
 	cfg.BindFlags(cmd.Flags())
 	i.BindFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&quiet, "quiet", false,
+		"only log warnings and errors, and print the name of the installed CSV on success")
 
 	return cmd
 }
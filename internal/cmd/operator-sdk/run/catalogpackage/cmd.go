@@ -0,0 +1,59 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalogpackage
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/catalogpackage"
+)
+
+func NewCmd(cfg *operator.Configuration) *cobra.Command {
+	i := catalogpackage.NewInstall(cfg)
+	cmd := &cobra.Command{
+		Use:   "package <package-name>",
+		Short: "Deploy an Operator from a CatalogSource already in the cluster with OLM",
+		Long: `The single argument to this command is the name of a package served by the CatalogSource set by ` + "`--catalog-source`" + `,
+ex. a package in the operatorhubio-catalog CatalogSource in the olm namespace.
+
+No catalog or registry pod is created: a Subscription to the package's ` + "`--channel`" + `, or its default channel,
+is created in the install namespace, and its channel head is installed. The package and channel are checked
+against the PackageManifests served by OLM's package server before subscribing.
+
+Since the CatalogSource was not created by this command, ` + "`operator-sdk cleanup`" + ` does not delete it.
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(*cobra.Command, []string) error { return cfg.Load() },
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, cancel := context.WithTimeout(cmd.Context(), cfg.Timeout)
+			defer cancel()
+
+			i.PackageName = args[0]
+
+			if _, err := i.Run(ctx); err != nil {
+				logrus.Fatalf("Failed to run package: %v\n", err)
+			}
+		},
+	}
+
+	cfg.BindFlags(cmd.Flags())
+	i.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundle"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/bundleupgrade"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/catalogpackage"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/run/packagemanifests"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 )
@@ -35,6 +36,7 @@ func NewCmd() *cobra.Command {
 	cmd.AddCommand(
 		bundle.NewCmd(cfg),
 		bundleupgrade.NewCmd(cfg),
+		catalogpackage.NewCmd(cfg),
 		packagemanifests.NewCmd(cfg),
 	)
 
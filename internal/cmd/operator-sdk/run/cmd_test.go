@@ -29,10 +29,11 @@ var _ = Describe("Running a run command", func() {
 			Expect(cmd.Long).NotTo(BeNil())
 
 			subcommands := cmd.Commands()
-			Expect(len(subcommands)).To(Equal(3))
+			Expect(len(subcommands)).To(Equal(4))
 			Expect(subcommands[0].Use).To(Equal("bundle <bundle-image>"))
 			Expect(subcommands[1].Use).To(Equal("bundle-upgrade <bundle-image>"))
-			Expect(subcommands[2].Use).To(Equal("packagemanifests [packagemanifests-root-dir]"))
+			Expect(subcommands[2].Use).To(Equal("package <package-name>"))
+			Expect(subcommands[3].Use).To(Equal("packagemanifests [packagemanifests-root-dir]"))
 		})
 	})
 })
@@ -15,19 +15,115 @@
 package bundle
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/blang/semver/v4"
+	"github.com/docker/distribution/reference"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 )
 
 type Install struct {
 	BundleImage string
+	// BundlesFile, if set, is a file listing additional bundle images, one per line,
+	// to add to the catalog before BundleImage.
+	BundlesFile string
+	// PriorBundles are earlier versions of BundleImage's package, oldest first, added to the catalog
+	// before BundleImage so the channel has an upgrade history ending at BundleImage.
+	PriorBundles []string
+	// RequireDigest causes setup to fail if IndexImage is not referenced by digest, unless ResolveDigest is set.
+	RequireDigest bool
+	// ResolveDigest pins a tag-referenced IndexImage to the digest it currently resolves to,
+	// so the catalog image and its recorded references do not drift during the run.
+	ResolveDigest bool
+	// ExtractBundleDir, if set, is a directory the loaded bundle's manifests are written to.
+	ExtractBundleDir string
+	// Force installs the bundle even if its CSV is already installed and has succeeded.
+	Force bool
+	// CatalogLabels and CatalogAnnotations are "key=value" pairs added to the created
+	// CatalogSource's metadata.
+	CatalogLabels      []string
+	CatalogAnnotations []string
+	// ValidateOnly validates BundleImage with ValidateBundleImage instead of installing it.
+	ValidateOnly bool
+	// PrintConfig prints the resolved configuration after setup and exits without installing.
+	PrintConfig bool
+	// Preflight checks that the bundle and index images can be resolved in their registries
+	// before anything is pulled or created.
+	Preflight bool
+	// StrictArch causes setup to fail, rather than warn, if no cluster node has a platform
+	// supported by the bundle's CSV.
+	StrictArch bool
+	// NamespaceSelector, if set, is a label selector matching exactly one namespace, which is installed into
+	// instead of the configured namespace.
+	NamespaceSelector string
+	// CreateNamespace creates the install namespace, labeled with PodSecurityLevel, if it does not exist.
+	CreateNamespace bool
+	// WatchNamespaces, if set, are the target namespaces of a SingleNamespace or MultiNamespace InstallMode.
+	WatchNamespaces []string
+	// CreateWatchNamespaces creates WatchNamespaces, labeled with PodSecurityLevel, that do not exist.
+	// Otherwise setup fails if any do not exist.
+	CreateWatchNamespaces bool
+	// PodSecurityLevel is the Pod Security level a namespace created by CreateNamespace
+	// or CreateWatchNamespaces enforces.
+	PodSecurityLevel string
+	// StrictCRD causes setup to fail, rather than warn, if a CRD owned by the bundle's CSV
+	// is already owned by another installed CSV.
+	StrictCRD bool
+	// BundleTemplate, if set, expands "name@version" bundle image shorthands into full references,
+	// ex. "quay.io/myorg/{name}:{version}".
+	BundleTemplate string
+	// PostInstall, if set, is called with the installed CSV after InstallOperator succeeds.
+	// An error it returns is returned by Run along with the CSV; the install is not rolled back.
+	PostInstall func(context.Context, *v1alpha1.ClusterServiceVersion) error
+	// PostInstallCheckFile, if set, is a manifest of objects created after the CSV succeeds,
+	// each of which must report PostInstallCondition for the install to pass.
+	PostInstallCheckFile string
+	// PostInstallCondition is the status condition type post-install check objects must report as "True".
+	PostInstallCondition string
+	// KeepTestResources skips deleting post-install check objects after the check.
+	KeepTestResources bool
+	// CompareWithInstalled prints the API and RBAC changes from the package's installed CSV to the bundle's CSV.
+	CompareWithInstalled bool
+	// Confirm allows CompareWithInstalled to proceed when the bundle's CSV removes owned CRDs.
+	Confirm bool
+	// TargetOLMVersion is the OLM version the bundle's features are checked against.
+	// If unset, the version installed in the cluster is used.
+	TargetOLMVersion string
+	// DryRun prints the cluster resources an install would create or reuse, then exits without installing.
+	DryRun bool
+	// CatalogSourceTemplateFile is a file containing a partial CatalogSource the created CatalogSource starts from.
+	CatalogSourceTemplateFile string
+	// SubscriptionChannel, if set, is the channel the Subscription uses instead of the bundle's first channel.
+	SubscriptionChannel string
+	// ReportFile, if set, is a file a JSON report of the run's stages, durations, and result is written to,
+	// whether or not the run succeeds.
+	ReportFile string
+	// PatchFile is a file containing a list of JSON patches applied to the generated
+	// CatalogSource and Subscription before they are created.
+	PatchFile string
 
 	*registry.IndexImageCatalogCreator
 	*registry.OperatorInstaller
@@ -42,12 +138,101 @@ func NewInstall(cfg *operator.Configuration) Install {
 	}
 	i.IndexImageCatalogCreator = registry.NewIndexImageCatalogCreator(cfg)
 	i.CatalogCreator = i.IndexImageCatalogCreator
+	i.Timer = &operator.StageTimer{}
+	i.SetLogger(operator.NewRunLogger())
 	return i
 }
 
+// SetLogger sets the logger the install and its catalog, registry pod, and stage timer log with.
+// NewInstall sets one tagged with a new run ID.
+func (i *Install) SetLogger(logger *log.Entry) {
+	i.OperatorInstaller.Logger = logger
+	i.IndexImageCatalogCreator.Logger = logger
+	i.Timer.Logger = logger
+}
+
 func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&i.IndexImage, "index-image", registry.DefaultIndexImage, "index image in which to inject bundle")
+	fs.BoolVar(&i.RequireDigest, "require-digest", false, "error if --index-image is referenced by tag instead of by digest, unless --resolve-digest is set")
+	fs.BoolVar(&i.ResolveDigest, "resolve-digest", false,
+		"resolve a tag-referenced --index-image to its digest and use the digest reference for the catalog")
 	fs.Var(&i.InstallMode, "install-mode", "install mode")
+	fs.BoolVar(&i.Preflight, "preflight", false,
+		"check that the bundle and index image registries are reachable before installing")
+	fs.BoolVar(&i.ValidateOnly, "validate-bundle", false,
+		"validate that the bundle is installable and exit without creating a catalog or contacting the cluster")
+	fs.BoolVar(&i.PrintConfig, "print-config", false,
+		"print the fully resolved configuration and exit without installing")
+	fs.BoolVar(&i.Timer.Log, "timings", false, "log the duration of each install stage")
+	fs.BoolVar(&i.StrictArch, "strict-arch", false,
+		"error instead of warning if no cluster node has an os and architecture supported by the bundle's CSV")
+	fs.StringVar(&i.NamespaceSelector, "namespace-selector", "",
+		"label selector matching exactly one namespace to install into, instead of --namespace")
+	fs.BoolVar(&i.CreateNamespace, "create-namespace", false,
+		"create the install namespace if it does not exist, labeled with the --psa-level Pod Security level")
+	fs.StringSliceVar(&i.WatchNamespaces, "watch-namespaces", nil,
+		"target namespaces of the OperatorGroup for --install-mode SingleNamespace or MultiNamespace")
+	fs.BoolVar(&i.CreateWatchNamespaces, "create-watch-namespaces", false,
+		"create --watch-namespaces that do not exist, labeled to enforce --psa-level")
+	fs.StringVar(&i.PodSecurityLevel, "psa-level", operator.PodSecurityLevelPrivileged,
+		"Pod Security level a namespace created by --create-namespace enforces, "+
+			"one of \"privileged\", \"baseline\", or \"restricted\"")
+	fs.BoolVar(&i.StrictCRD, "strict-crd", false,
+		"error instead of warning if a CRD owned by the bundle's CSV is already owned by another installed CSV")
+	fs.StringVar(&i.PostInstallCheckFile, "post-install-check", "",
+		"manifest of objects, ex. a sample CR, to create once the CSV succeeds and wait for "+
+			"until each reports the --post-install-condition")
+	fs.StringVar(&i.PostInstallCondition, "post-install-condition", defaultPostInstallCondition,
+		"status condition type post-install check objects must report as \"True\"")
+	fs.BoolVar(&i.KeepTestResources, "keep-test-resources", false,
+		"do not delete post-install check objects after the check")
+	fs.BoolVar(&i.CompareWithInstalled, "compare-with-installed", false,
+		"print the API and RBAC changes from the package's installed CSV to the bundle's CSV, "+
+			"failing if owned CRDs are removed unless --confirm is set")
+	fs.BoolVar(&i.Confirm, "confirm", false,
+		"with --compare-with-installed, install the bundle even if its CSV removes owned CRDs")
+	fs.StringVar(&i.TargetOLMVersion, "target-olm-version", "",
+		"OLM version to check the bundle's features against, instead of the version installed in the cluster")
+	fs.BoolVar(&i.DryRun, "dry-run", false,
+		"print the cluster resources that would be created or reused, then exit without installing")
+	fs.BoolVar(&i.Force, "force", false,
+		"install the bundle even if its CSV is already installed and has succeeded in the namespace")
+	fs.StringVar(&i.BundleTemplate, "bundle-template", "",
+		"template used to expand \"name@version\" bundle image shorthands, "+
+			"ex. \"quay.io/myorg/{name}:{version}\"")
+	fs.StringVar(&i.SubscriptionChannel, "subscription-channel", "",
+		"channel the subscription uses, instead of the first channel in the bundle's channels label. "+
+			"If not a bundle channel, the install fails if the served catalog does not publish the package in this channel")
+	fs.StringVar(&i.ReportFile, "report-file", "",
+		"file to write a JSON report of install stages, their durations, and the result to, even if the install fails")
+	fs.StringVar(&i.PatchFile, "patch", "",
+		"file containing a list of {kind, patch} pairs, where each patch is an RFC 6902 JSON patch "+
+			"applied to the generated CatalogSource or Subscription before it is created")
+	fs.StringVar(&i.BundlesFile, "bundles-file", "",
+		"file listing additional bundle images, one per line, to add to the index before the installed bundle. "+
+			"Blank lines and lines starting with '#' are ignored")
+	fs.StringSliceVar(&i.PriorBundles, "prior-bundles", nil,
+		"earlier bundle images of the package, oldest first, to add to the index before the installed bundle. "+
+			"Versions must be strictly increasing, ending at the installed bundle")
+	fs.StringVar(&i.ExtractBundleDir, "extract-bundle-to", "",
+		"write the bundle's manifests to this directory for inspection")
+	fs.StringArrayVar(&i.CatalogLabels, "catalog-label", nil,
+		"label in the form key=value to add to the created catalog source. May be specified more than once")
+	fs.StringArrayVar(&i.CatalogAnnotations, "catalog-annotation", nil,
+		"annotation in the form key=value to add to the created catalog source. May be specified more than once")
+	fs.StringVar(&i.CatalogSourceTemplateFile, "catalog-source-template", "",
+		"file containing a partial CatalogSource whose labels, annotations, display metadata, priority, "+
+			"and secrets the created catalog source starts from")
+	fs.StringVar(&i.DisplayName, "catalog-display-name", "",
+		"display name of the created catalog source; defaults to the bundle's package name")
+	fs.StringVar(&i.Publisher, "catalog-publisher", "",
+		"publisher of the created catalog source; defaults to \"operator-sdk\"")
+
+	fs.StringVar(&i.InheritCatalogConfig, "inherit-catalog-config", "",
+		"name of an existing catalog source in the namespace whose priority, display metadata, "+
+			"and secrets are copied to the created catalog source")
+	fs.BoolVar(&i.PrintInstallPlan, "print-install-plan", false,
+		"print the resources in the generated install plan before approving it")
 
 	// --mode is hidden so only users who know what they're doing can alter add mode.
 	fs.StringVar((*string)(&i.BundleAddMode), "mode", "", "mode to use for adding bundle to index")
@@ -56,45 +241,782 @@ func (i *Install) BindFlags(fs *pflag.FlagSet) {
 	i.IndexImageCatalogCreator.BindFlags(fs)
 }
 
-func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
-	if err := i.setup(ctx); err != nil {
+func (i *Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	start := time.Now()
+	ctx, span := operator.StartSpan(ctx, "run bundle",
+		attribute.String("bundle.image", i.BundleImage),
+		attribute.String("index.image", i.IndexImage),
+		attribute.String("namespace", i.cfg.Namespace))
+	csv, err := i.run(ctx)
+	operator.EndSpan(span, err)
+	if i.ReportFile != "" {
+		if rerr := writeReport(i.ReportFile, newInstallReport(*i, start, time.Now(), csv, err)); rerr != nil {
+			i.GetLogger().Warnf("Failed to write report: %v", rerr)
+		}
+	}
+	return csv, err
+}
+
+func (i *Install) run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	setupCtx, done := i.Timer.Track(ctx, "setup")
+	err := i.setup(setupCtx)
+	done(err)
+	if err != nil {
 		return nil, err
 	}
-	return i.InstallOperator(ctx)
+	if i.PrintConfig {
+		return nil, i.printConfig(os.Stdout)
+	}
+	if i.DryRun {
+		return nil, i.printPlan(ctx, os.Stdout)
+	}
+	if !i.Force {
+		csv, err := i.GetSucceededCSV(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if csv != nil {
+			i.GetLogger().Infof("%q is already installed in namespace %q, skipping install. Use --force to reinstall",
+				csv.GetName(), i.cfg.Namespace)
+			return csv, nil
+		}
+	}
+	for _, ns := range i.namespacesToCreate() {
+		created, err := operator.EnsureNamespace(ctx, i.cfg.Client, ns, i.PodSecurityLevel)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			i.GetLogger().Infof("Created Namespace: %s", ns)
+		}
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("package", i.OperatorInstaller.PackageName))
+	csv, err := i.InstallOperator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if i.PostInstall == nil && i.PostInstallCheckFile == "" {
+		return csv, nil
+	}
+	postInstallCtx, done := i.Timer.Track(ctx, "post-install")
+	err = i.runPostInstall(postInstallCtx, csv)
+	if err == nil {
+		err = i.runPostInstallCheck(postInstallCtx)
+	}
+	done(err)
+	if err != nil {
+		return csv, err
+	}
+	return csv, nil
 }
 
-func (i *Install) setup(ctx context.Context) error {
+// runPostInstallCheck runs the post-install check, if PostInstallCheckFile is set, and reports the result.
+func (i Install) runPostInstallCheck(ctx context.Context) error {
+	if i.PostInstallCheckFile == "" {
+		return nil
+	}
+	check := postInstallCheck{
+		ManifestFile:  i.PostInstallCheckFile,
+		Condition:     i.PostInstallCondition,
+		KeepResources: i.KeepTestResources,
+	}
+	i.GetLogger().Infof("Running post-install check %s", i.PostInstallCheckFile)
+	if err := check.run(ctx, i.cfg.Client, i.cfg.Namespace); err != nil {
+		return fmt.Errorf("post-install check failed: %v", err)
+	}
+	i.GetLogger().Infof("Post-install check passed")
+	return nil
+}
+
+// runPostInstall calls PostInstall, if set, with the installed csv.
+func (i Install) runPostInstall(ctx context.Context, csv *v1alpha1.ClusterServiceVersion) error {
+	if i.PostInstall == nil {
+		return nil
+	}
+	if err := i.PostInstall(ctx, csv); err != nil {
+		return fmt.Errorf("post-install hook for %q: %v", csv.GetName(), err)
+	}
+	return nil
+}
+
+// Validate checks that configured options and their combinations are valid,
+// returning a single error describing every invalid option. If ValidateOnly is set,
+// only the options --validate-bundle uses are checked.
+func (i Install) Validate() error {
+	errs := i.validateBundleFlags()
+	if !i.ValidateOnly {
+		errs = append(errs, i.validateInstallFlags()...)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateBundleFlags returns errors for the options used to load and check BundleImage,
+// which are the only options --validate-bundle uses.
+func (i Install) validateBundleFlags() (errs []error) {
+	if _, err := i.installMode(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := expandBundleImage(i.BundleTemplate, i.BundleImage); err != nil {
+		errs = append(errs, err)
+	}
+	if i.ValidateOnly {
+		for _, flag := range i.setInstallOnlyFlags() {
+			errs = append(errs, fmt.Errorf("--%s cannot be set with --validate-bundle", flag))
+		}
+	}
+	return errs
+}
+
+// validateInstallFlags returns errors for the options that only affect installing BundleImage.
+func (i Install) validateInstallFlags() (errs []error) {
 	// Validate add mode in case it was set by a user.
 	if i.BundleAddMode != "" {
 		if err := i.BundleAddMode.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if isDigest, err := operator.IsDigestReference(i.IndexImage); err != nil {
+		errs = append(errs, fmt.Errorf("invalid index image: %v", err))
+	} else if i.RequireDigest && !isDigest && !i.ResolveDigest {
+		// A tagged image is pinned to its digest in setup if ResolveDigest is set.
+		errs = append(errs, fmt.Errorf("index image %q must be referenced by digest when --require-digest is set", i.IndexImage))
+	}
+
+	if err := i.SecurityContextConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := index.ValidatePullPolicy(i.CatalogPullPolicy); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --catalog-pull-policy: %v", err))
+	}
+	if err := index.ValidateGRPCPort(i.GRPCPort); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --catalog-grpc-port: %v", err))
+	}
+
+	if i.NamespaceSelector != "" {
+		if _, err := labels.Parse(i.NamespaceSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --namespace-selector: %v", err))
+		}
+		if i.CreateNamespace {
+			errs = append(errs, fmt.Errorf("--create-namespace cannot be set with --namespace-selector"))
+		}
+	}
+
+	if i.CreateNamespace || i.CreateWatchNamespaces {
+		if err := operator.ValidatePodSecurityLevel(i.PodSecurityLevel); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --psa-level: %v", err))
+		}
+	}
+
+	if i.PostInstallCheckFile != "" && i.PostInstallCondition == "" {
+		errs = append(errs, fmt.Errorf("--post-install-condition must be set when --post-install-check is set"))
+	}
+	if i.KeepTestResources && i.PostInstallCheckFile == "" {
+		errs = append(errs, fmt.Errorf("--post-install-check must be set when --keep-test-resources is set"))
+	}
+	if i.Confirm && !i.CompareWithInstalled {
+		errs = append(errs, fmt.Errorf("--compare-with-installed must be set when --confirm is set"))
+	}
+	if i.CreateWatchNamespaces && len(i.WatchNamespaces) == 0 {
+		errs = append(errs, fmt.Errorf("--watch-namespaces must be set when --create-watch-namespaces is set"))
+	}
+	if i.DryRun {
+		if i.Force {
+			errs = append(errs, fmt.Errorf("--force cannot be set with --dry-run"))
+		}
+		if i.PrintConfig {
+			errs = append(errs, fmt.Errorf("--print-config cannot be set with --dry-run"))
+		}
+	}
+
+	if i.TargetOLMVersion != "" {
+		if _, err := semver.ParseTolerant(i.TargetOLMVersion); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --target-olm-version %q: %v", i.TargetOLMVersion, err))
+		}
+	}
+
+	c := *i.IndexImageCatalogCreator
+	var err error
+	if c.Labels, err = parseKeyValuePairs(i.CatalogLabels); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --catalog-label: %v", err))
+	}
+	if c.Annotations, err = parseKeyValuePairs(i.CatalogAnnotations); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --catalog-annotation: %v", err))
+	}
+	if err := c.ValidateMetadata(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// setInstallOnlyFlags returns the names of set flags that only affect installing the bundle,
+// which --validate-bundle does not do.
+func (i Install) setInstallOnlyFlags() (flags []string) {
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"dry-run", i.DryRun},
+		{"print-config", i.PrintConfig},
+		{"force", i.Force},
+		{"namespace-selector", i.NamespaceSelector != ""},
+		{"create-namespace", i.CreateNamespace},
+		{"create-watch-namespaces", i.CreateWatchNamespaces},
+		{"post-install-check", i.PostInstallCheckFile != ""},
+		{"compare-with-installed", i.CompareWithInstalled},
+		{"patch", i.PatchFile != ""},
+		{"report-file", i.ReportFile != ""},
+		{"print-install-plan", i.PrintInstallPlan},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return flags
+}
+
+func (i *Install) setup(ctx context.Context) error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+
+	if i.NamespaceSelector != "" {
+		// The selector was validated above.
+		selector, _ := labels.Parse(i.NamespaceSelector)
+		ns, err := operator.GetNamespaceBySelector(ctx, i.cfg.Client, selector)
+		if err != nil {
 			return err
 		}
+		i.cfg.Namespace = ns
+		i.GetLogger().Infof("Installing into namespace %q, selected by %q", ns, i.NamespaceSelector)
 	}
 
+	// Labels and annotations were validated above.
+	i.IndexImageCatalogCreator.Labels, _ = parseKeyValuePairs(i.CatalogLabels)
+	i.IndexImageCatalogCreator.Annotations, _ = parseKeyValuePairs(i.CatalogAnnotations)
+
 	//if user sets --skip-tls then set --use-http to true as --skip-tls is deprecated
 	if i.SkipTLS {
 		i.UseHTTP = true
 	}
 
+	if i.ResolveDigest {
+		if err := i.resolveIndexImageDigest(ctx); err != nil {
+			return err
+		}
+	}
+	i.warnIndexImageTag()
+
+	// The bundle image and install mode were validated above.
+	i.BundleImage, _ = expandBundleImage(i.BundleTemplate, i.BundleImage)
+	i.InstallMode, _ = i.installMode()
+
+	if len(i.WatchNamespaces) != 0 && !i.CreateWatchNamespaces {
+		missing, err := operator.GetMissingNamespaces(ctx, i.cfg.Client, i.WatchNamespaces)
+		if err != nil {
+			return err
+		}
+		if len(missing) != 0 {
+			return fmt.Errorf("watch namespaces %q do not exist; create them or set --create-watch-namespaces", missing)
+		}
+	}
+
+	if i.CatalogSourceTemplateFile != "" {
+		tmpl, err := readCatalogSourceTemplate(i.CatalogSourceTemplateFile)
+		if err != nil {
+			return err
+		}
+		i.IndexImageCatalogCreator.CatalogSourceTemplate = tmpl
+		if err := i.IndexImageCatalogCreator.ValidateTemplate(); err != nil {
+			return err
+		}
+	}
+
+	if i.PatchFile != "" {
+		patches, err := readPatchFile(i.PatchFile)
+		if err != nil {
+			return err
+		}
+		i.IndexImageCatalogCreator.Patches = patches
+		i.OperatorInstaller.Patches = patches
+	}
+
+	// Catch an invalid post-install check manifest before anything is installed.
+	if i.PostInstallCheckFile != "" {
+		if _, err := readManifestFile(i.PostInstallCheckFile); err != nil {
+			return err
+		}
+	}
+
+	if i.BundlesFile != "" {
+		bundleImages, err := readBundlesFile(i.BundlesFile)
+		if err != nil {
+			return err
+		}
+		for j, image := range bundleImages {
+			if bundleImages[j], err = expandBundleImage(i.BundleTemplate, image); err != nil {
+				return err
+			}
+		}
+		i.IndexImageCatalogCreator.AdditionalBundleImages = bundleImages
+	}
+
+	if i.Preflight {
+		preflightCtx, done := i.Timer.Track(ctx, "preflight")
+		err := i.checkRegistries(preflightCtx)
+		done(err)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Load bundle labels and set label-dependent values.
-	labels, bundle, err := operator.LoadBundle(ctx, i.BundleImage, i.SkipTLSVerify, i.UseHTTP)
+	loadCtx, done := i.Timer.Track(ctx, "load bundle")
+	labels, bundle, err := operator.LoadBundle(loadCtx, i.BundleImage, i.SkipTLSVerify, i.UseHTTP)
+	done(err)
 	if err != nil {
 		return err
 	}
 	csv := bundle.CSV
 
+	if len(i.PriorBundles) != 0 {
+		if err := i.addPriorBundles(ctx, labels[registrybundle.PackageLabel], csv); err != nil {
+			return err
+		}
+	}
+
+	if i.ExtractBundleDir != "" {
+		if err := operator.WriteBundleObjects(i.ExtractBundleDir, bundle); err != nil {
+			return err
+		}
+		i.GetLogger().Infof("Extracted bundle manifests to %s", i.ExtractBundleDir)
+	}
+
 	if err := i.InstallMode.CheckCompatibility(csv, i.cfg.Namespace); err != nil {
 		return err
 	}
 
+	i.checkOLMVersion(ctx, bundle)
+
+	if err := i.checkPlatforms(ctx, csv); err != nil {
+		if i.StrictArch {
+			return err
+		}
+		i.GetLogger().Warnf("Operator pods may not be schedulable: %v", err)
+	}
+	if err := operator.CheckCRDConflicts(ctx, i.cfg.Client, csv, labels[registrybundle.PackageLabel]); apierrors.IsForbidden(err) {
+		i.GetLogger().Debugf("Skipping CRD conflict check: %v", err)
+	} else if err != nil {
+		if i.StrictCRD {
+			return err
+		}
+		i.GetLogger().Warnf("OLM may not install the bundle: %v", err)
+	}
+
 	i.OperatorInstaller.PackageName = labels[registrybundle.PackageLabel]
-	i.OperatorInstaller.CatalogSourceName = operator.CatalogNameForPackage(i.OperatorInstaller.PackageName)
+	i.OperatorInstaller.SetCatalogSourceName()
 	i.OperatorInstaller.StartingCSV = csv.Name
 	i.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(csv.Spec.InstallModes)
-	i.OperatorInstaller.Channel = strings.Split(labels[registrybundle.ChannelsLabel], ",")[0]
+	channels, err := operator.GetChannels(labels)
+	if err != nil {
+		return err
+	}
+	i.OperatorInstaller.Channel, i.OperatorInstaller.RequireServedChannel = i.subscriptionChannel(channels)
 
 	i.IndexImageCatalogCreator.PackageName = i.OperatorInstaller.PackageName
 	i.IndexImageCatalogCreator.BundleImage = i.BundleImage
 
+	if i.CompareWithInstalled {
+		if err := operator.CheckUpgradeImpact(ctx, i.cfg.Client, os.Stdout, i.cfg.Namespace,
+			i.OperatorInstaller.PackageName, csv, i.Confirm); err != nil {
+			return err
+		}
+	}
+
+	// Catch OperatorGroup conflicts before any catalog or registry pod is created.
+	if err := i.OperatorInstaller.CheckOperatorGroup(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// installMode returns InstallMode with its target namespaces set to WatchNamespaces, if set.
+func (i Install) installMode() (operator.InstallMode, error) {
+	mode := i.InstallMode
+	if len(i.WatchNamespaces) != 0 {
+		switch mode.InstallModeType {
+		case v1alpha1.InstallModeTypeSingleNamespace, v1alpha1.InstallModeTypeMultiNamespace:
+		default:
+			return mode, fmt.Errorf("--watch-namespaces requires --install-mode %s or %s",
+				v1alpha1.InstallModeTypeSingleNamespace, v1alpha1.InstallModeTypeMultiNamespace)
+		}
+		if len(mode.TargetNamespaces) != 0 {
+			return mode, fmt.Errorf("target namespaces must be set by either --install-mode or --watch-namespaces, not both")
+		}
+		mode.TargetNamespaces = append([]string{}, i.WatchNamespaces...)
+		sort.Strings(mode.TargetNamespaces)
+	}
+	if err := mode.Validate(); err != nil {
+		return mode, fmt.Errorf("invalid --install-mode: %v", err)
+	}
+	return mode, nil
+}
+
+// checkOLMVersion warns if the bundle uses features that the target OLM version does not support.
+func (i Install) checkOLMVersion(ctx context.Context, bundle *apimanifests.Bundle) {
+	olmVersion := i.TargetOLMVersion
+	if olmVersion == "" {
+		var err error
+		if olmVersion, err = operator.GetOLMVersion(ctx, i.cfg.Client); err != nil {
+			i.GetLogger().Debugf("Skipping OLM version check: %v", err)
+			return
+		}
+	}
+	if err := operator.CheckOLMVersionSupport(bundle, olmVersion); err != nil {
+		i.GetLogger().Warn(err)
+	}
+}
+
+// checkPlatforms returns an error if no cluster node has a platform supported by csv.
+// The check is skipped if nodes cannot be listed or none are listed, ex. without RBAC to list nodes.
+func (i Install) checkPlatforms(ctx context.Context, csv *v1alpha1.ClusterServiceVersion) error {
+	platforms, err := operator.GetNodePlatforms(ctx, i.cfg.Client)
+	switch {
+	case apierrors.IsForbidden(err):
+		i.GetLogger().Debugf("Skipping platform compatibility check: %v", err)
+		return nil
+	case err != nil:
+		return err
+	case len(platforms) == 0:
+		i.GetLogger().Debugf("Skipping platform compatibility check: no nodes listed")
+		return nil
+	}
+	return operator.CheckPlatformCompatibility(csv, platforms)
+}
+
+// subscriptionChannel returns SubscriptionChannel if set, otherwise the first of the bundle's channels,
+// and whether the channel is not a bundle channel so must be checked against the served catalog.
+func (i Install) subscriptionChannel(channels []string) (string, bool) {
+	if i.SubscriptionChannel == "" {
+		return channels[0], false
+	}
+	for _, ch := range channels {
+		if ch == i.SubscriptionChannel {
+			return ch, false
+		}
+	}
+	i.GetLogger().Warnf("Subscription channel %q is not one of the bundle's channels %+q; "+
+		"the install will fail if index image %q does not publish package %q in that channel",
+		i.SubscriptionChannel, channels, i.IndexImage, i.OperatorInstaller.PackageName)
+	return i.SubscriptionChannel, true
+}
+
+// resolveIndexImageDigest replaces a tag-referenced IndexImage with a reference to its current digest.
+func (i *Install) resolveIndexImageDigest(ctx context.Context) error {
+	// The index image was validated by Validate.
+	if isDigest, _ := operator.IsDigestReference(i.IndexImage); isDigest {
+		return nil
+	}
+	pinned, err := registryutil.ResolveImageDigest(ctx, i.IndexImage, i.SkipTLSVerify, i.UseHTTP)
+	if err != nil {
+		return fmt.Errorf("resolve index image digest: %v", err)
+	}
+	i.GetLogger().Infof("Resolved index image %q to %q", i.IndexImage, pinned)
+	i.IndexImage = pinned
 	return nil
 }
+
+// warnIndexImageTag warns when the index image is referenced by a mutable tag,
+// since the injected catalog can then drift between runs.
+func (i Install) warnIndexImageTag() {
+	// The default index image contains no bundles, so drift is not a concern.
+	if i.IndexImage == registry.DefaultIndexImage {
+		return
+	}
+	if isDigest, err := operator.IsDigestReference(i.IndexImage); err == nil && !isDigest {
+		i.GetLogger().Warnf("Index image %q is referenced by tag; the injected catalog may change between runs. "+
+			"Reference the index image by digest for reproducible installs", i.IndexImage)
+	}
+}
+
+// checkRegistries resolves the bundle, prior bundle, and index images in their registries.
+func (i Install) checkRegistries(ctx context.Context) error {
+	images, err := i.preflightImages()
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		if err := registryutil.CheckImageReachable(ctx, image, i.SkipTLSVerify, i.UseHTTP); err != nil {
+			return fmt.Errorf("preflight check failed: %v", err)
+		}
+		i.GetLogger().Infof("Preflight: image %s is reachable", image)
+	}
+	return nil
+}
+
+// preflightImages returns every image the catalog is built from: BundleImage, IndexImage,
+// additional bundles, and PriorBundles expanded with BundleTemplate.
+func (i Install) preflightImages() ([]string, error) {
+	images := append([]string{i.BundleImage, i.IndexImage}, i.AdditionalBundleImages...)
+	for _, image := range i.PriorBundles {
+		image, err := expandBundleImage(i.BundleTemplate, image)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// addPriorBundles loads PriorBundles, checks that they form an upgrade chain ending at csv,
+// and adds them to the catalog immediately before BundleImage.
+func (i *Install) addPriorBundles(ctx context.Context, pkg string, csv *v1alpha1.ClusterServiceVersion) error {
+	var priors []priorBundle
+	for _, image := range i.PriorBundles {
+		image, err := expandBundleImage(i.BundleTemplate, image)
+		if err != nil {
+			return err
+		}
+		labels, bundle, err := operator.LoadBundle(ctx, image, i.SkipTLSVerify, i.UseHTTP)
+		if err != nil {
+			return fmt.Errorf("load prior bundle %q: %v", image, err)
+		}
+		priors = append(priors, priorBundle{image: image, pkg: labels[registrybundle.PackageLabel], csv: bundle.CSV})
+	}
+	head := priorBundle{image: i.BundleImage, pkg: pkg, csv: csv}
+	checkReplaces := i.IndexImageCatalogCreator.EffectiveBundleAddMode() == index.ReplacesBundleAddMode
+	if err := validatePriorBundles(priors, head, checkReplaces); err != nil {
+		return err
+	}
+	for _, prior := range priors {
+		i.IndexImageCatalogCreator.AdditionalBundleImages = append(i.IndexImageCatalogCreator.AdditionalBundleImages, prior.image)
+	}
+	return nil
+}
+
+// priorBundle is a loaded bundle in an upgrade chain.
+type priorBundle struct {
+	image string
+	pkg   string
+	csv   *v1alpha1.ClusterServiceVersion
+}
+
+// validatePriorBundles returns an error if priors followed by head are not bundles of one package
+// with strictly increasing versions. If checkReplaces is true, as in replaces add mode where edges
+// come from each CSV's spec.replaces, each CSV must also replace the CSV before it.
+func validatePriorBundles(priors []priorBundle, head priorBundle, checkReplaces bool) error {
+	chain := append(append([]priorBundle{}, priors...), head)
+	for j, b := range chain {
+		if b.pkg != head.pkg {
+			return fmt.Errorf("prior bundle %q is in package %q, not %q", b.image, b.pkg, head.pkg)
+		}
+		if j == 0 {
+			continue
+		}
+		prev := chain[j-1]
+		if !b.csv.Spec.Version.GT(prev.csv.Spec.Version.Version) {
+			return fmt.Errorf("bundle %q version %s is not greater than prior bundle %q version %s",
+				b.image, b.csv.Spec.Version, prev.image, prev.csv.Spec.Version)
+		}
+		if checkReplaces && b.csv.Spec.Replaces != prev.csv.GetName() {
+			return fmt.Errorf("bundle %q CSV %q replaces %q, not prior bundle %q CSV %q",
+				b.image, b.csv.GetName(), b.csv.Spec.Replaces, prev.image, prev.csv.GetName())
+		}
+	}
+	return nil
+}
+
+// readBundlesFile returns the bundle images listed in path, one per line,
+// ignoring blank lines and lines starting with '#'.
+func readBundlesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open bundles file: %v", err)
+	}
+	defer f.Close()
+
+	var images []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		images = append(images, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read bundles file: %v", err)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("bundles file %s lists no bundle images", path)
+	}
+	return images, nil
+}
+
+// expandBundleImage expands image with template if image is a "name@version" shorthand,
+// and returns an error if the expanded reference cannot be parsed.
+// image is returned unchanged if template is empty or image is not a shorthand.
+func expandBundleImage(template, image string) (string, error) {
+	if template == "" {
+		return image, nil
+	}
+	// Full references contain a registry or repository path, and digests contain a ':'.
+	split := strings.Split(image, "@")
+	if len(split) != 2 || strings.ContainsAny(image, "/:") {
+		return image, nil
+	}
+	name, version := split[0], split[1]
+	if name == "" || version == "" {
+		return "", fmt.Errorf("bundle image shorthand %q must be in the form name@version", image)
+	}
+
+	expanded := strings.NewReplacer("{name}", name, "{version}", version).Replace(template)
+	if _, err := reference.ParseNormalizedNamed(expanded); err != nil {
+		return "", fmt.Errorf("bundle image %q expanded with template %q to invalid reference %q: %v",
+			image, template, expanded, err)
+	}
+	return expanded, nil
+}
+
+// readCatalogSourceTemplate reads a CatalogSource from the YAML or JSON file at path.
+func readCatalogSourceTemplate(path string) (*v1alpha1.CatalogSource, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read catalog source template: %v", err)
+	}
+	tmpl := &v1alpha1.CatalogSource{}
+	if err := yaml.UnmarshalStrict(b, tmpl); err != nil {
+		return nil, fmt.Errorf("parse catalog source template %s: %v", path, err)
+	}
+	if gvk := tmpl.GroupVersionKind(); gvk != v1alpha1.SchemeGroupVersion.WithKind(v1alpha1.CatalogSourceKind) {
+		return nil, fmt.Errorf("catalog source template %s must have apiVersion %q and kind %q",
+			path, v1alpha1.SchemeGroupVersion, v1alpha1.CatalogSourceKind)
+	}
+	return tmpl, nil
+}
+
+// readPatchFile reads a list of resource patches from the YAML or JSON file at path.
+func readPatchFile(path string) ([]registry.ResourcePatch, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read patch file: %v", err)
+	}
+	var patches []registry.ResourcePatch
+	if err := yaml.UnmarshalStrict(b, &patches); err != nil {
+		return nil, fmt.Errorf("parse patch file %s: %v", path, err)
+	}
+	if err := registry.ValidatePatches(patches); err != nil {
+		return nil, fmt.Errorf("invalid patch file %s: %v", path, err)
+	}
+	return patches, nil
+}
+
+// parseKeyValuePairs parses "key=value" pairs into a map.
+func parseKeyValuePairs(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("%q must be in the form key=value", pair)
+		}
+		if _, exists := m[split[0]]; exists {
+			return nil, fmt.Errorf("key %q set more than once", split[0])
+		}
+		m[split[0]] = split[1]
+	}
+	return m, nil
+}
+
+// namespacesToCreate returns the namespaces --create-namespace and --create-watch-namespaces create
+// if they do not exist.
+func (i Install) namespacesToCreate() []string {
+	var namespaces []string
+	if i.CreateNamespace {
+		namespaces = append(namespaces, i.cfg.Namespace)
+	}
+	if i.CreateWatchNamespaces {
+		namespaces = append(namespaces, i.WatchNamespaces...)
+	}
+	return namespaces
+}
+
+// printPlan writes the resources an install would create or reuse to w.
+func (i Install) printPlan(ctx context.Context, w io.Writer) error {
+	resources, err := registry.PlanNamespaces(ctx, i.cfg.Client, i.namespacesToCreate(), i.PodSecurityLevel)
+	if err != nil {
+		return err
+	}
+	catalogResources, err := i.IndexImageCatalogCreator.PlanCatalog(ctx, i.CatalogSourceName)
+	if err != nil {
+		return err
+	}
+	installResources, err := i.OperatorInstaller.PlanInstall(ctx)
+	if err != nil {
+		return err
+	}
+	resources = append(append(resources, catalogResources...), installResources...)
+	return registry.WritePlan(w, i.cfg.Namespace, resources)
+}
+
+// effectiveConfig is the resolved configuration of an Install, printed by --print-config.
+type effectiveConfig struct {
+	Namespace              string            `json:"namespace"`
+	ServiceAccount         string            `json:"serviceAccount,omitempty"`
+	Timeout                string            `json:"timeout"`
+	BundleImage            string            `json:"bundleImage"`
+	AdditionalBundleImages []string          `json:"additionalBundleImages,omitempty"`
+	IndexImage             string            `json:"indexImage"`
+	BundleAddMode          string            `json:"bundleAddMode"`
+	PackageName            string            `json:"packageName"`
+	Channel                string            `json:"channel"`
+	StartingCSV            string            `json:"startingCSV"`
+	InstallMode            string            `json:"installMode,omitempty"`
+	SupportedInstallModes  []string          `json:"supportedInstallModes"`
+	CatalogSourceName      string            `json:"catalogSourceName"`
+	CatalogDisplayName     string            `json:"catalogDisplayName,omitempty"`
+	CatalogPublisher       string            `json:"catalogPublisher,omitempty"`
+	CatalogLabels          map[string]string `json:"catalogLabels,omitempty"`
+	CatalogAnnotations     map[string]string `json:"catalogAnnotations,omitempty"`
+	PullSecretName         string            `json:"pullSecretName,omitempty"`
+	CASecretName           string            `json:"caSecretName,omitempty"`
+	SkipTLSVerify          bool              `json:"skipTLSVerify"`
+	UseHTTP                bool              `json:"useHTTP"`
+}
+
+// printConfig writes the resolved configuration to w as YAML.
+func (i Install) printConfig(w io.Writer) error {
+	cfg := effectiveConfig{
+		Namespace:              i.cfg.Namespace,
+		ServiceAccount:         i.cfg.ServiceAccount,
+		Timeout:                i.cfg.Timeout.String(),
+		BundleImage:            i.BundleImage,
+		AdditionalBundleImages: i.AdditionalBundleImages,
+		IndexImage:             i.IndexImage,
+		BundleAddMode:          string(i.EffectiveBundleAddMode()),
+		PackageName:            i.OperatorInstaller.PackageName,
+		Channel:                i.Channel,
+		StartingCSV:            i.StartingCSV,
+		InstallMode:            i.InstallMode.String(),
+		SupportedInstallModes:  i.SupportedInstallModes.List(),
+		CatalogSourceName:      i.CatalogSourceName,
+		CatalogDisplayName:     i.DisplayName,
+		CatalogPublisher:       i.Publisher,
+		CatalogLabels:          i.IndexImageCatalogCreator.Labels,
+		CatalogAnnotations:     i.IndexImageCatalogCreator.Annotations,
+		PullSecretName:         i.SecretName,
+		CASecretName:           i.CASecretName,
+		SkipTLSVerify:          i.SkipTLSVerify,
+		UseHTTP:                i.UseHTTP,
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshal configuration: %v", err)
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,590 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blang/semver/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+)
+
+var _ = Describe("Install", func() {
+
+	Describe("Validate", func() {
+		var i Install
+		BeforeEach(func() {
+			i = NewInstall(&operator.Configuration{})
+			i.IndexImage = registry.DefaultIndexImage
+		})
+
+		It("should succeed with defaults", func() {
+			Expect(i.Validate()).To(Succeed())
+		})
+		It("should return an error for an invalid add mode", func() {
+			i.BundleAddMode = "foo"
+			Expect(i.Validate()).To(MatchError(ContainSubstring(`bundle add mode "foo" does not exist`)))
+		})
+		It("should return an error for a tagged index image if a digest is required", func() {
+			i.RequireDigest = true
+			Expect(i.Validate()).To(MatchError(ContainSubstring("must be referenced by digest")))
+		})
+		It("should return an error for an invalid target OLM version", func() {
+			i.TargetOLMVersion = "latest"
+			Expect(i.Validate()).To(MatchError(ContainSubstring(`invalid --target-olm-version "latest"`)))
+		})
+		It("should return all errors at once", func() {
+			i.BundleAddMode = "foo"
+			i.RequireDigest = true
+			i.CatalogLabels = []string{"novalue"}
+			err := i.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("bundle add mode"))
+			Expect(err.Error()).To(ContainSubstring("must be referenced by digest"))
+			Expect(err.Error()).To(ContainSubstring("invalid --catalog-label"))
+		})
+		It("should return an error for an invalid namespace selector", func() {
+			i.NamespaceSelector = "env in (pr"
+			Expect(i.Validate()).To(MatchError(ContainSubstring("invalid --namespace-selector")))
+		})
+		It("should return an error for a namespace selector with --create-namespace", func() {
+			i.NamespaceSelector = "env=pr-1"
+			i.CreateNamespace = true
+			Expect(i.Validate()).To(MatchError(ContainSubstring("--create-namespace cannot be set with --namespace-selector")))
+		})
+		It("should return an error for reserved catalog annotations", func() {
+			i.CatalogAnnotations = []string{"operators.operatorframework.io/index-image=foo"}
+			Expect(i.Validate()).To(MatchError(ContainSubstring("is reserved")))
+		})
+		DescribeTable("should return an error for conflicting flags",
+			func(set func(*Install), msg string) {
+				set(&i)
+				Expect(i.Validate()).To(MatchError(ContainSubstring(msg)))
+			},
+			Entry("--dry-run with --force", func(i *Install) {
+				i.DryRun, i.Force = true, true
+			}, "--force cannot be set with --dry-run"),
+			Entry("--dry-run with --print-config", func(i *Install) {
+				i.DryRun, i.PrintConfig = true, true
+			}, "--print-config cannot be set with --dry-run"),
+			Entry("--confirm without --compare-with-installed", func(i *Install) {
+				i.Confirm = true
+			}, "--compare-with-installed must be set when --confirm is set"),
+			Entry("--create-watch-namespaces without --watch-namespaces", func(i *Install) {
+				i.CreateWatchNamespaces = true
+			}, "--watch-namespaces must be set when --create-watch-namespaces is set"),
+			Entry("--keep-test-resources without --post-install-check", func(i *Install) {
+				i.KeepTestResources = true
+			}, "--post-install-check must be set when --keep-test-resources is set"),
+			Entry("--validate-bundle with --create-namespace", func(i *Install) {
+				i.ValidateOnly, i.CreateNamespace = true, true
+			}, "--create-namespace cannot be set with --validate-bundle"),
+			Entry("--validate-bundle with --patch", func(i *Install) {
+				i.ValidateOnly, i.PatchFile = true, "patch.yaml"
+			}, "--patch cannot be set with --validate-bundle"),
+		)
+		DescribeTable("should allow flags with the flags they depend on",
+			func(set func(*Install)) {
+				set(&i)
+				Expect(i.Validate()).To(Succeed())
+			},
+			Entry("--confirm with --compare-with-installed", func(i *Install) {
+				i.Confirm, i.CompareWithInstalled = true, true
+			}),
+			Entry("--create-watch-namespaces with --watch-namespaces", func(i *Install) {
+				i.CreateWatchNamespaces, i.WatchNamespaces = true, []string{"ns1"}
+				i.InstallMode = operator.InstallMode{InstallModeType: v1alpha1.InstallModeTypeMultiNamespace}
+				i.PodSecurityLevel = operator.PodSecurityLevelPrivileged
+			}),
+			Entry("--require-digest with --resolve-digest", func(i *Install) {
+				i.RequireDigest, i.ResolveDigest = true, true
+			}),
+			Entry("--validate-bundle with bundle flags", func(i *Install) {
+				i.ValidateOnly, i.BundleTemplate = true, "quay.io/example/{name}:{version}"
+			}),
+			Entry("--validate-bundle with a SingleNamespace install mode", func(i *Install) {
+				i.ValidateOnly = true
+				Expect(i.InstallMode.Set("SingleNamespace=testns")).To(Succeed())
+			}),
+			Entry("--validate-bundle without checking install options", func(i *Install) {
+				i.ValidateOnly, i.RequireDigest, i.CatalogPullPolicy = true, true, "Sometimes"
+			}),
+		)
+	})
+
+	Describe("installMode", func() {
+		var i Install
+		BeforeEach(func() {
+			i = NewInstall(&operator.Configuration{})
+		})
+
+		It("should set sorted target namespaces from --watch-namespaces", func() {
+			Expect(i.InstallMode.Set("MultiNamespace")).To(Succeed())
+			i.WatchNamespaces = []string{"ns2", "ns1"}
+			mode, err := i.installMode()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mode.TargetNamespaces).To(Equal([]string{"ns1", "ns2"}))
+		})
+		It("should return an error for a namespaced install mode without target namespaces", func() {
+			Expect(i.InstallMode.Set("MultiNamespace")).To(Succeed())
+			_, err := i.installMode()
+			Expect(err).To(MatchError(ContainSubstring("must have at least one target namespace")))
+		})
+		It("should return an error for --watch-namespaces with a non-namespaced install mode", func() {
+			Expect(i.InstallMode.Set("AllNamespaces")).To(Succeed())
+			i.WatchNamespaces = []string{"ns1"}
+			_, err := i.installMode()
+			Expect(err).To(MatchError(ContainSubstring("--watch-namespaces requires --install-mode")))
+		})
+		It("should return an error if target namespaces are set twice", func() {
+			Expect(i.InstallMode.Set("MultiNamespace=ns1")).To(Succeed())
+			i.WatchNamespaces = []string{"ns2"}
+			_, err := i.installMode()
+			Expect(err).To(MatchError(ContainSubstring("not both")))
+		})
+		It("should return an error for too many SingleNamespace target namespaces", func() {
+			Expect(i.InstallMode.Set("SingleNamespace")).To(Succeed())
+			i.WatchNamespaces = []string{"ns1", "ns2"}
+			_, err := i.installMode()
+			Expect(err).To(MatchError(ContainSubstring("must have exactly one target namespace")))
+		})
+	})
+
+	Describe("runPostInstall", func() {
+		var (
+			i   Install
+			csv *v1alpha1.ClusterServiceVersion
+		)
+		BeforeEach(func() {
+			i = NewInstall(&operator.Configuration{})
+			csv = &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator.v0.0.1"}}
+		})
+
+		It("should succeed if no hook is set", func() {
+			Expect(i.runPostInstall(context.TODO(), csv)).To(Succeed())
+		})
+		It("should call the hook with the installed CSV", func() {
+			var called *v1alpha1.ClusterServiceVersion
+			i.PostInstall = func(_ context.Context, c *v1alpha1.ClusterServiceVersion) error {
+				called = c
+				return nil
+			}
+			Expect(i.runPostInstall(context.TODO(), csv)).To(Succeed())
+			Expect(called).To(Equal(csv))
+		})
+		It("should return the hook's error", func() {
+			i.PostInstall = func(context.Context, *v1alpha1.ClusterServiceVersion) error {
+				return errors.New("patch failed")
+			}
+			Expect(i.runPostInstall(context.TODO(), csv)).To(MatchError(`post-install hook for "memcached-operator.v0.0.1": patch failed`))
+		})
+	})
+
+	Describe("subscriptionChannel", func() {
+		var i Install
+		BeforeEach(func() {
+			i = NewInstall(&operator.Configuration{})
+		})
+
+		It("should default to the bundle's first channel", func() {
+			ch, requireServed := i.subscriptionChannel([]string{"stable", "beta"})
+			Expect(ch).To(Equal("stable"))
+			Expect(requireServed).To(BeFalse())
+		})
+		It("should return a bundle channel if set", func() {
+			i.SubscriptionChannel = "beta"
+			ch, requireServed := i.subscriptionChannel([]string{"stable", "beta"})
+			Expect(ch).To(Equal("beta"))
+			Expect(requireServed).To(BeFalse())
+		})
+		It("should return a channel the bundle does not declare if set, to be checked against the served catalog", func() {
+			i.SubscriptionChannel = "fast"
+			ch, requireServed := i.subscriptionChannel([]string{"stable"})
+			Expect(ch).To(Equal("fast"))
+			Expect(requireServed).To(BeTrue())
+		})
+	})
+
+	Describe("resolveIndexImageDigest", func() {
+		var i Install
+		BeforeEach(func() {
+			i = NewInstall(&operator.Configuration{})
+		})
+
+		It("should not change an index image referenced by digest", func() {
+			image := "quay.io/example/index@sha256:" + strings.Repeat("a", 64)
+			i.IndexImage = image
+			Expect(i.resolveIndexImageDigest(context.TODO())).To(Succeed())
+			Expect(i.IndexImage).To(Equal(image))
+		})
+		It("should return an error and not change the index image if its registry is unreachable", func() {
+			i.IndexImage = "localhost:1/example/index:latest"
+			i.UseHTTP = true
+			Expect(i.resolveIndexImageDigest(context.TODO())).To(MatchError(ContainSubstring("resolve index image digest")))
+			Expect(i.IndexImage).To(Equal("localhost:1/example/index:latest"))
+		})
+	})
+
+	Describe("preflightImages", func() {
+		It("should include additional and expanded prior bundles", func() {
+			i := NewInstall(&operator.Configuration{})
+			i.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.3"
+			i.IndexImage = registry.DefaultIndexImage
+			i.AdditionalBundleImages = []string{"quay.io/example/etcd-bundle:v0.1.0"}
+			i.BundleTemplate = "quay.io/example/{name}-bundle:{version}"
+			i.PriorBundles = []string{"memcached-operator@v0.0.1", "quay.io/example/memcached-operator-bundle:v0.0.2"}
+			Expect(i.preflightImages()).To(Equal([]string{
+				"quay.io/example/memcached-operator-bundle:v0.0.3",
+				registry.DefaultIndexImage,
+				"quay.io/example/etcd-bundle:v0.1.0",
+				"quay.io/example/memcached-operator-bundle:v0.0.1",
+				"quay.io/example/memcached-operator-bundle:v0.0.2",
+			}))
+		})
+	})
+
+	Describe("readBundlesFile", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "bundles-file-")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		writeBundlesFile := func(contents string) string {
+			path := filepath.Join(dir, "bundles.txt")
+			Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+			return path
+		}
+
+		It("should return bundle images ignoring blank lines and comments", func() {
+			path := writeBundlesFile(`# dependencies
+quay.io/example/foo-bundle:v0.0.1
+
+  quay.io/example/bar-bundle:v0.0.2  
+`)
+			images, err := readBundlesFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(Equal([]string{"quay.io/example/foo-bundle:v0.0.1", "quay.io/example/bar-bundle:v0.0.2"}))
+		})
+		It("should return an error if no bundle images are listed", func() {
+			path := writeBundlesFile("# nothing here\n\n")
+			_, err := readBundlesFile(path)
+			Expect(err).To(MatchError(ContainSubstring("lists no bundle images")))
+		})
+		It("should return an error if the file does not exist", func() {
+			_, err := readBundlesFile(filepath.Join(dir, "missing.txt"))
+			Expect(err).To(MatchError(ContainSubstring("open bundles file")))
+		})
+	})
+	Describe("expandBundleImage", func() {
+		const template = "quay.io/example/{name}:{version}"
+
+		It("should return the image unchanged if no template is set", func() {
+			Expect(expandBundleImage("", "memcached@v0.0.1")).To(Equal("memcached@v0.0.1"))
+		})
+		It("should expand a shorthand with the template", func() {
+			Expect(expandBundleImage(template, "memcached-operator-bundle@v0.0.1")).
+				To(Equal("quay.io/example/memcached-operator-bundle:v0.0.1"))
+		})
+		It("should return full references unchanged", func() {
+			for _, image := range []string{
+				"quay.io/example/memcached-operator-bundle:v0.0.1",
+				"quay.io/example/memcached-operator-bundle@sha256:" +
+					"0000000000000000000000000000000000000000000000000000000000000000",
+				"memcached-operator-bundle",
+			} {
+				Expect(expandBundleImage(template, image)).To(Equal(image))
+			}
+		})
+		It("should return an error for an incomplete shorthand", func() {
+			_, err := expandBundleImage(template, "memcached@")
+			Expect(err).To(MatchError(ContainSubstring("must be in the form name@version")))
+		})
+		It("should return an error if the expanded reference is invalid", func() {
+			_, err := expandBundleImage("quay.io/example/{name}:{version}", "Memcached@v0.0.1")
+			Expect(err).To(MatchError(ContainSubstring("to invalid reference")))
+		})
+	})
+
+	Describe("readCatalogSourceTemplate", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "catalog-source-template-")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		writeTemplate := func(content string) string {
+			path := filepath.Join(dir, "catalogsource.yaml")
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		It("should read a catalog source", func() {
+			path := writeTemplate(`apiVersion: operators.coreos.com/v1alpha1
+kind: CatalogSource
+metadata:
+  labels:
+    team: a
+spec:
+  priority: 5
+`)
+			tmpl, err := readCatalogSourceTemplate(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tmpl.GetLabels()).To(HaveKeyWithValue("team", "a"))
+			Expect(tmpl.Spec.Priority).To(Equal(5))
+		})
+		It("should return an error for a different kind", func() {
+			path := writeTemplate("apiVersion: operators.coreos.com/v1alpha1\nkind: Subscription\n")
+			_, err := readCatalogSourceTemplate(path)
+			Expect(err).To(MatchError(ContainSubstring(`must have apiVersion "operators.coreos.com/v1alpha1" and kind "CatalogSource"`)))
+		})
+		It("should return an error for unknown fields", func() {
+			path := writeTemplate("apiVersion: operators.coreos.com/v1alpha1\nkind: CatalogSource\nspec:\n  prority: 5\n")
+			_, err := readCatalogSourceTemplate(path)
+			Expect(err).To(MatchError(ContainSubstring("parse catalog source template")))
+		})
+	})
+
+	Describe("readPatchFile", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "patch-file-")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		writePatchFile := func(content string) string {
+			path := filepath.Join(dir, "patches.yaml")
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		It("should read a list of patches", func() {
+			path := writePatchFile(`- kind: Subscription
+  patch:
+  - op: add
+    path: /spec/config
+    value:
+      nodeSelector:
+        kubernetes.io/os: linux
+`)
+			patches, err := readPatchFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patches).To(HaveLen(1))
+			Expect(patches[0].Kind).To(Equal("Subscription"))
+		})
+		It("should return an error for an invalid patch", func() {
+			path := writePatchFile("- kind: Deployment\n  patch: []\n")
+			_, err := readPatchFile(path)
+			Expect(err).To(MatchError(ContainSubstring(`kind "Deployment" cannot be patched`)))
+		})
+	})
+
+	Describe("parseKeyValuePairs", func() {
+		It("should return nil for no pairs", func() {
+			m, err := parseKeyValuePairs(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m).To(BeNil())
+		})
+		It("should parse key=value pairs", func() {
+			m, err := parseKeyValuePairs([]string{"a=b", "example.com/c=d=e", "empty="})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m).To(Equal(map[string]string{"a": "b", "example.com/c": "d=e", "empty": ""}))
+		})
+		It("should return an error for a pair with no separator", func() {
+			_, err := parseKeyValuePairs([]string{"a"})
+			Expect(err).To(MatchError(ContainSubstring("must be in the form key=value")))
+		})
+		It("should return an error for a pair with no key", func() {
+			_, err := parseKeyValuePairs([]string{"=b"})
+			Expect(err).To(MatchError(ContainSubstring("must be in the form key=value")))
+		})
+		It("should return an error for a duplicate key", func() {
+			_, err := parseKeyValuePairs([]string{"a=b", "a=c"})
+			Expect(err).To(MatchError(ContainSubstring("set more than once")))
+		})
+	})
+	Describe("Run", func() {
+		It("should record a setup error on the root span", func() {
+			exporter := tracetest.NewInMemoryExporter()
+			// The default global provider delegates to the first provider set, so restore a no-op one.
+			defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+			i := NewInstall(&operator.Configuration{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()})
+			i.IndexImage = registry.DefaultIndexImage
+			i.BundlesFile = filepath.Join("testdata", "does-not-exist")
+			_, err := i.Run(context.TODO())
+			Expect(err).To(HaveOccurred())
+
+			spans := exporter.GetSpans()
+			Expect(spans).ToNot(BeEmpty())
+			root := spans[len(spans)-1]
+			Expect(root.Name).To(Equal("run bundle"))
+			Expect(root.StatusCode).To(Equal(codes.Error))
+			Expect(root.StatusMessage).To(Equal(err.Error()))
+		})
+		It("should tag each concurrent install's logs with its own run ID", func() {
+			hooks := log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+			defer log.StandardLogger().ReplaceHooks(hooks)
+			hook := logtest.NewLocal(log.StandardLogger())
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "testns", Labels: map[string]string{"env": "pr-1"}}}
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ns).Build()
+
+			installs := make([]Install, 2)
+			var wg sync.WaitGroup
+			for j := range installs {
+				installs[j] = NewInstall(&operator.Configuration{Client: c})
+				installs[j].IndexImage = registry.DefaultIndexImage
+				installs[j].NamespaceSelector = "env=pr-1"
+				installs[j].BundlesFile = filepath.Join("testdata", "does-not-exist")
+				wg.Add(1)
+				go func(i *Install) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := i.Run(context.TODO())
+					Expect(err).To(HaveOccurred())
+				}(&installs[j])
+			}
+			wg.Wait()
+
+			ids := map[interface{}]bool{}
+			for _, e := range hook.AllEntries() {
+				ids[e.Data[operator.RunIDField]] = true
+			}
+			Expect(ids).To(HaveLen(2))
+			for _, i := range installs {
+				Expect(ids).To(HaveKey(i.GetLogger().Data[operator.RunIDField]))
+			}
+		})
+	})
+
+	Describe("checkPlatforms", func() {
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+
+		It("should skip the check if nodes cannot be listed", func() {
+			c := forbiddenListClient{fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+			i := NewInstall(&operator.Configuration{Client: c})
+			Expect(i.checkPlatforms(context.TODO(), csv)).To(Succeed())
+		})
+		It("should skip the check if no nodes are listed", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			i := NewInstall(&operator.Configuration{Client: c})
+			Expect(i.checkPlatforms(context.TODO(), csv)).To(Succeed())
+		})
+	})
+	Describe("validatePriorBundles", func() {
+		newBundle := func(pkg, name, ver, replaces string) priorBundle {
+			csv := &v1alpha1.ClusterServiceVersion{}
+			csv.SetName(name)
+			csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse(ver)}
+			csv.Spec.Replaces = replaces
+			return priorBundle{image: "quay.io/example/bundle:" + ver, pkg: pkg, csv: csv}
+		}
+		var v1, v2, head priorBundle
+		BeforeEach(func() {
+			v1 = newBundle("foo", "foo.v0.1.0", "0.1.0", "")
+			v2 = newBundle("foo", "foo.v0.2.0", "0.2.0", "foo.v0.1.0")
+			head = newBundle("foo", "foo.v0.3.0", "0.3.0", "foo.v0.2.0")
+		})
+		It("should accept an upgrade chain ending at the head bundle", func() {
+			Expect(validatePriorBundles([]priorBundle{v1, v2}, head, true)).To(Succeed())
+		})
+		It("should return an error for a prior bundle in another package", func() {
+			v1.pkg = "bar"
+			err := validatePriorBundles([]priorBundle{v1, v2}, head, false)
+			Expect(err).To(MatchError(`prior bundle "quay.io/example/bundle:0.1.0" is in package "bar", not "foo"`))
+		})
+		It("should return an error for versions out of order", func() {
+			err := validatePriorBundles([]priorBundle{v2, v1}, head, false)
+			Expect(err).To(MatchError(ContainSubstring("version 0.1.0 is not greater than prior bundle")))
+		})
+		It("should only check replaces when requested", func() {
+			head.csv.Spec.Replaces = "foo.v0.1.0"
+			Expect(validatePriorBundles([]priorBundle{v1, v2}, head, false)).To(Succeed())
+			err := validatePriorBundles([]priorBundle{v1, v2}, head, true)
+			Expect(err).To(MatchError(ContainSubstring(`replaces "foo.v0.1.0", not prior bundle`)))
+		})
+	})
+	Describe("printConfig", func() {
+		It("should print the resolved configuration", func() {
+			i := NewInstall(&operator.Configuration{Namespace: "testns", Timeout: time.Minute})
+			i.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+			i.IndexImage = registry.DefaultIndexImage
+			i.OperatorInstaller.PackageName = "memcached-operator"
+			i.Channel = "alpha"
+			i.SupportedInstallModes = sets.NewString("AllNamespaces")
+
+			out := &bytes.Buffer{}
+			Expect(i.printConfig(out)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("namespace: testns\n"))
+			Expect(out.String()).To(ContainSubstring("timeout: 1m0s\n"))
+			Expect(out.String()).To(ContainSubstring("bundleImage: quay.io/example/memcached-operator-bundle:v0.0.1\n"))
+			Expect(out.String()).To(ContainSubstring("bundleAddMode: semver\n"))
+			Expect(out.String()).To(ContainSubstring("packageName: memcached-operator\n"))
+			Expect(out.String()).To(ContainSubstring("channel: alpha\n"))
+			Expect(out.String()).To(ContainSubstring("- AllNamespaces\n"))
+		})
+	})
+})
+
+// forbiddenListClient returns a Forbidden error from every List.
+type forbiddenListClient struct {
+	client.Client
+}
+
+func (c forbiddenListClient) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("no RBAC"))
+}
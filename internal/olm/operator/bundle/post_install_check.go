@@ -0,0 +1,170 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultPostInstallCondition is the status condition a post-install check waits for by default.
+const defaultPostInstallCondition = "Ready"
+
+// postInstallCheck creates the objects in a manifest file once the operator is installed, then waits
+// for each to report a condition, ex. to verify a sample CR is reconciled.
+type postInstallCheck struct {
+	// ManifestFile contains the objects to create.
+	ManifestFile string
+	// Condition is the type of the status condition each object must report as "True".
+	Condition string
+	// KeepResources skips deleting the created objects after the check.
+	KeepResources bool
+}
+
+// run creates the check's objects in namespace and waits for them to report the check's condition.
+// Created objects are deleted before run returns unless KeepResources is set.
+func (p postInstallCheck) run(ctx context.Context, c client.Client, namespace string) (err error) {
+	objs, err := readManifestFile(p.ManifestFile)
+	if err != nil {
+		return err
+	}
+
+	var created []*unstructured.Unstructured
+	defer func() {
+		if p.KeepResources {
+			return
+		}
+		// Clean up even if ctx is done, since the check's objects are test resources.
+		for _, obj := range created {
+			if delErr := c.Delete(context.Background(), obj); client.IgnoreNotFound(delErr) != nil && err == nil {
+				err = fmt.Errorf("delete %s: %v", describeObject(obj), delErr)
+			}
+		}
+	}()
+
+	for _, obj := range objs {
+		if err := setDefaultNamespace(c, obj, namespace); err != nil {
+			return err
+		}
+		if err := c.Create(ctx, obj); err != nil {
+			return fmt.Errorf("create %s: %v", describeObject(obj), err)
+		}
+		created = append(created, obj)
+	}
+
+	for _, obj := range created {
+		if err := p.waitForCondition(ctx, c, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForCondition polls obj until it reports the check's condition as "True".
+func (p postInstallCheck) waitForCondition(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
+	var lastMessage string
+	check := wait.ConditionFunc(func() (bool, error) {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return false, err
+		}
+		status, message, found := getCondition(obj, p.Condition)
+		lastMessage = message
+		return found && status == "True", nil
+	})
+	if err := wait.PollImmediateUntil(time.Second, check, ctx.Done()); err != nil {
+		if lastMessage != "" {
+			return fmt.Errorf("%s did not report condition %q: %v: %s", describeObject(obj), p.Condition, err, lastMessage)
+		}
+		return fmt.Errorf("%s did not report condition %q: %v", describeObject(obj), p.Condition, err)
+	}
+	return nil
+}
+
+// getCondition returns the status and message of obj's status condition of type condType.
+func getCondition(obj *unstructured.Unstructured, condType string) (status, message string, found bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != condType {
+			continue
+		}
+		status, _ = cond["status"].(string)
+		message, _ = cond["message"].(string)
+		return status, message, true
+	}
+	return "", "", false
+}
+
+// setDefaultNamespace sets obj's namespace to namespace if obj is namespace-scoped and has none.
+func setDefaultNamespace(c client.Client, obj *unstructured.Unstructured, namespace string) error {
+	if obj.GetNamespace() != "" {
+		return nil
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("get scope of %s: %v", describeObject(obj), err)
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		obj.SetNamespace(namespace)
+	}
+	return nil
+}
+
+// readManifestFile decodes every object in the YAML or JSON manifest file path.
+func readManifestFile(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open post-install check manifest: %v", err)
+	}
+	defer f.Close()
+
+	var objs []*unstructured.Unstructured
+	dec := k8syaml.NewYAMLOrJSONDecoder(f, 1024)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := dec.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decode post-install check manifest %s: %v", path, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("post-install check manifest %s: every object must have a kind and name", path)
+		}
+		objs = append(objs, obj)
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("post-install check manifest %s contains no objects", path)
+	}
+	return objs, nil
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s %q", obj.GetKind(), client.ObjectKeyFromObject(obj))
+}
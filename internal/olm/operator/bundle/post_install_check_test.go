@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("postInstallCheck", func() {
+	const readyCR = `apiVersion: cache.example.com/v1alpha1
+kind: Memcached
+metadata:
+  name: memcached-sample
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+`
+	const pendingCR = `apiVersion: cache.example.com/v1alpha1
+kind: Memcached
+metadata:
+  name: memcached-pending
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+    message: waiting for pods
+`
+	gvk := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+
+	var (
+		dir string
+		c   client.Client
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "post-install-check-")
+		Expect(err).ToNot(HaveOccurred())
+
+		sch := runtime.NewScheme()
+		sch.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		sch.AddKnownTypeWithName(gvk.GroupVersion().WithKind("MemcachedList"), &unstructured.UnstructuredList{})
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+		c = fake.NewClientBuilder().WithScheme(sch).WithRESTMapper(mapper).Build()
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	writeManifest := func(content string) string {
+		path := filepath.Join(dir, "check.yaml")
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+	getCR := func(name string) error {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		return c.Get(context.TODO(), client.ObjectKey{Namespace: "testns", Name: name}, obj)
+	}
+
+	It("should pass and delete the objects once they report the condition", func() {
+		check := postInstallCheck{ManifestFile: writeManifest(readyCR), Condition: "Ready"}
+		Expect(check.run(context.TODO(), c, "testns")).To(Succeed())
+		Expect(getCR("memcached-sample")).To(MatchError(ContainSubstring("not found")))
+	})
+	It("should keep the objects if KeepResources is set", func() {
+		check := postInstallCheck{ManifestFile: writeManifest(readyCR), Condition: "Ready", KeepResources: true}
+		Expect(check.run(context.TODO(), c, "testns")).To(Succeed())
+		Expect(getCR("memcached-sample")).To(Succeed())
+	})
+	It("should fail with the condition's message if an object does not report the condition", func() {
+		check := postInstallCheck{ManifestFile: writeManifest(readyCR + "---\n" + pendingCR), Condition: "Ready"}
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		err := check.run(ctx, c, "testns")
+		Expect(err).To(MatchError(ContainSubstring(`Memcached "testns/memcached-pending" did not report condition "Ready"`)))
+		Expect(err).To(MatchError(ContainSubstring("waiting for pods")))
+		Expect(getCR("memcached-sample")).To(MatchError(ContainSubstring("not found")))
+		Expect(getCR("memcached-pending")).To(MatchError(ContainSubstring("not found")))
+	})
+	It("should return an error if the manifest contains no objects", func() {
+		check := postInstallCheck{ManifestFile: writeManifest("---\n"), Condition: "Ready"}
+		Expect(check.run(context.TODO(), c, "testns")).To(MatchError(ContainSubstring("contains no objects")))
+	})
+})
@@ -0,0 +1,86 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// installReport is the result of a run, written to a ReportFile as JSON.
+type installReport struct {
+	BundleImage     string        `json:"bundleImage"`
+	IndexImage      string        `json:"indexImage"`
+	Namespace       string        `json:"namespace"`
+	CatalogSource   string        `json:"catalogSource,omitempty"`
+	CSV             string        `json:"csv,omitempty"`
+	Succeeded       bool          `json:"succeeded"`
+	FailedStage     string        `json:"failedStage,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Stages          []reportStage `json:"stages"`
+	StartTime       time.Time     `json:"startTime"`
+	CompletionTime  time.Time     `json:"completionTime"`
+}
+
+// reportStage is the duration of a recorded install stage, which may have failed.
+type reportStage struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// newInstallReport returns a report of a run that started at start and returned csv and runErr.
+func newInstallReport(i Install, start, end time.Time, csv *v1alpha1.ClusterServiceVersion, runErr error) installReport {
+	r := installReport{
+		BundleImage:     i.BundleImage,
+		IndexImage:      i.IndexImage,
+		Namespace:       i.cfg.Namespace,
+		CatalogSource:   i.CatalogSourceName,
+		Succeeded:       runErr == nil,
+		DurationSeconds: end.Sub(start).Seconds(),
+		Stages:          []reportStage{},
+		StartTime:       start.UTC(),
+		CompletionTime:  end.UTC(),
+	}
+	if csv != nil {
+		r.CSV = csv.GetName()
+	}
+	if runErr != nil {
+		r.Error = runErr.Error()
+		r.FailedStage = i.Timer.FailedStage()
+	}
+	if i.Timer != nil {
+		for _, s := range i.Timer.Stages {
+			r.Stages = append(r.Stages, reportStage{Name: s.Name, DurationSeconds: s.Duration.Seconds()})
+		}
+	}
+	return r
+}
+
+// writeReport writes r to path as indented JSON.
+func writeReport(path string, r installReport) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("write report file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("installReport", func() {
+	var (
+		i     Install
+		start time.Time
+	)
+	BeforeEach(func() {
+		i = NewInstall(&operator.Configuration{Namespace: "testns"})
+		i.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+		i.CatalogSourceName = "memcached-operator-catalog"
+		start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("should report a successful install", func() {
+		_, done := i.Timer.Track(context.TODO(), "load bundle")
+		done(nil)
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+
+		r := newInstallReport(i, start, start.Add(90*time.Second), csv, nil)
+		Expect(r.Succeeded).To(BeTrue())
+		Expect(r.CSV).To(Equal("memcached-operator.v0.0.1"))
+		Expect(r.CatalogSource).To(Equal("memcached-operator-catalog"))
+		Expect(r.Namespace).To(Equal("testns"))
+		Expect(r.DurationSeconds).To(Equal(90.0))
+		Expect(r.Stages).To(HaveLen(1))
+		Expect(r.Stages[0].Name).To(Equal("load bundle"))
+		Expect(r.FailedStage).To(BeEmpty())
+	})
+	It("should report the stage that failed and its error", func() {
+		_, done := i.Timer.Track(context.TODO(), "setup")
+		done(nil)
+		err := errors.New("timed out")
+		_, done = i.Timer.Track(context.TODO(), "wait for CSV")
+		done(err)
+
+		r := newInstallReport(i, start, start.Add(time.Minute), nil, err)
+		Expect(r.Succeeded).To(BeFalse())
+		Expect(r.FailedStage).To(Equal("wait for CSV"))
+		Expect(r.Error).To(Equal("timed out"))
+		Expect(r.CSV).To(BeEmpty())
+		Expect(r.Stages).To(HaveLen(2))
+	})
+	It("should write the report as JSON", func() {
+		dir, err := ioutil.TempDir("", "report")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "report.json")
+
+		Expect(writeReport(path, newInstallReport(i, start, start, nil, errors.New("failed")))).To(Succeed())
+		b, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		out := map[string]interface{}{}
+		Expect(json.Unmarshal(b, &out)).To(Succeed())
+		Expect(out).To(HaveKeyWithValue("bundleImage", i.BundleImage))
+		Expect(out).To(HaveKeyWithValue("succeeded", false))
+		Expect(out).To(HaveKeyWithValue("error", "failed"))
+		Expect(out).To(HaveKeyWithValue("stages", BeEmpty()))
+	})
+})
@@ -0,0 +1,82 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// ValidateBundleImage loads BundleImage and reports every issue that would prevent it from being
+// installed, without creating a catalog or contacting the cluster. Install modes are checked against
+// the --namespace value, if set, since the install namespace cannot be resolved without a cluster.
+func (i Install) ValidateBundleImage(ctx context.Context) error {
+	if err := utilerrors.NewAggregate(i.validateBundleFlags()); err != nil {
+		return err
+	}
+	// The bundle image and install mode were validated above.
+	image, _ := expandBundleImage(i.BundleTemplate, i.BundleImage)
+	mode, _ := i.installMode()
+	labels, bundle, err := operator.LoadBundle(ctx, image, i.SkipTLSVerify, i.UseHTTP)
+	if err != nil {
+		return err
+	}
+	warnings, errs := validateBundle(labels, bundle, mode, i.cfg.FlagNamespace())
+	for _, w := range warnings {
+		i.GetLogger().Warn(w)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("bundle %q is not installable: %v", image, utilerrors.NewAggregate(errs))
+	}
+	i.GetLogger().Infof("Bundle %q is valid", image)
+	return nil
+}
+
+// validateBundle returns warnings and errors for bundle, and its metadata labels, that would affect
+// installing it in namespace with installMode.
+func validateBundle(labels registryutil.Labels, bundle *apimanifests.Bundle, installMode operator.InstallMode, namespace string) (warnings []string, errs []error) {
+	if labels[registrybundle.PackageLabel] == "" {
+		errs = append(errs, fmt.Errorf("bundle metadata is missing label %q", registrybundle.PackageLabel))
+	}
+	if channels, err := operator.GetChannels(labels); err != nil {
+		errs = append(errs, err)
+	} else if def := labels[registrybundle.ChannelDefaultLabel]; def != "" && !sets.NewString(channels...).Has(def) {
+		errs = append(errs, fmt.Errorf("default channel %q is not one of the bundle's channels %q", def, channels))
+	}
+
+	if bundle.CSV != nil {
+		if err := installMode.CheckCompatibility(bundle.CSV, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, result := range registryutil.ValidateBundleContent(nil, bundle, labels[registrybundle.MediatypeLabel]) {
+		for _, e := range result.Errors {
+			errs = append(errs, e)
+		}
+		for _, w := range result.Warnings {
+			warnings = append(warnings, w.Error())
+		}
+	}
+	return warnings, errs
+}
@@ -0,0 +1,90 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("validateBundle", func() {
+	var (
+		labels registryutil.Labels
+		bundle *apimanifests.Bundle
+	)
+	BeforeEach(func() {
+		labels = registryutil.Labels{
+			registrybundle.MediatypeLabel:      registrybundle.RegistryV1Type,
+			registrybundle.PackageLabel:        "memcached-operator",
+			registrybundle.ChannelsLabel:       "alpha,stable",
+			registrybundle.ChannelDefaultLabel: "stable",
+		}
+		csv := &v1alpha1.ClusterServiceVersion{}
+		csv.SetName("memcached-operator.v0.0.1")
+		csv.Spec.InstallModes = []v1alpha1.InstallMode{{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true}}
+		bundle = &apimanifests.Bundle{Name: csv.GetName(), CSV: csv}
+	})
+
+	errorString := func(errs []error) string {
+		return utilerrors.NewAggregate(errs).Error()
+	}
+
+	It("should not report label or install mode errors for a valid bundle", func() {
+		_, errs := validateBundle(labels, bundle, operator.InstallMode{}, "default")
+		Expect(errs).NotTo(BeEmpty()) // The minimal CSV is missing required fields.
+		Expect(errorString(errs)).NotTo(ContainSubstring("label"))
+		Expect(errorString(errs)).NotTo(ContainSubstring("install mode"))
+	})
+	It("should report every missing label", func() {
+		delete(labels, registrybundle.PackageLabel)
+		delete(labels, registrybundle.ChannelsLabel)
+		_, errs := validateBundle(labels, bundle, operator.InstallMode{}, "default")
+		Expect(errorString(errs)).To(ContainSubstring(`missing label "` + registrybundle.PackageLabel + `"`))
+		Expect(errorString(errs)).To(ContainSubstring(`no channels declared in bundle label "` + registrybundle.ChannelsLabel + `"`))
+	})
+	It("should report a default channel that is not one of the bundle's channels", func() {
+		labels[registrybundle.ChannelDefaultLabel] = "fast"
+		_, errs := validateBundle(labels, bundle, operator.InstallMode{}, "default")
+		Expect(errorString(errs)).To(ContainSubstring(`default channel "fast" is not one of the bundle's channels`))
+	})
+	It("should report an unsupported install mode", func() {
+		bundle.CSV.Spec.InstallModes = append(bundle.CSV.Spec.InstallModes,
+			v1alpha1.InstallMode{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false})
+		mode := operator.InstallMode{InstallModeType: v1alpha1.InstallModeTypeAllNamespaces}
+		_, errs := validateBundle(labels, bundle, mode, "default")
+		Expect(errorString(errs)).To(ContainSubstring(`install mode type "AllNamespaces" not supported`))
+	})
+	It("should check a SingleNamespace install mode against the namespace only if it is known", func() {
+		bundle.CSV.Spec.InstallModes = []v1alpha1.InstallMode{{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: true}}
+		mode := operator.InstallMode{}
+		Expect(mode.Set("SingleNamespace=testns")).To(Succeed())
+		_, errs := validateBundle(labels, bundle, mode, "")
+		Expect(errorString(errs)).NotTo(ContainSubstring("install mode"))
+		_, errs = validateBundle(labels, bundle, mode, "testns")
+		Expect(errorString(errs)).To(ContainSubstring(`use install mode "OwnNamespace" to watch operator's namespace "testns"`))
+	})
+	It("should report a CSV with no install modes", func() {
+		bundle.CSV.Spec.InstallModes = nil
+		_, errs := validateBundle(labels, bundle, operator.InstallMode{}, "default")
+		Expect(errorString(errs)).To(ContainSubstring("no supported install modes"))
+	})
+})
@@ -16,7 +16,7 @@ package bundleupgrade
 
 import (
 	"context"
-	"strings"
+	"os"
 
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
@@ -28,6 +28,10 @@ import (
 
 type Upgrade struct {
 	BundleImage string
+	// CompareWithInstalled prints the API and RBAC changes from the package's installed CSV to the bundle's CSV.
+	CompareWithInstalled bool
+	// Confirm allows CompareWithInstalled to proceed when the bundle's CSV removes owned CRDs.
+	Confirm bool
 
 	*registry.IndexImageCatalogCreator
 	*registry.OperatorInstaller
@@ -42,10 +46,22 @@ func NewUpgrade(cfg *operator.Configuration) Upgrade {
 	}
 	u.IndexImageCatalogCreator = registry.NewIndexImageCatalogCreator(cfg)
 	u.CatalogUpdater = u.IndexImageCatalogCreator
+	logger := operator.NewRunLogger()
+	u.OperatorInstaller.Logger = logger
+	u.IndexImageCatalogCreator.Logger = logger
 	return u
 }
 
 func (u *Upgrade) BindFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&u.PrintInstallPlan, "print-install-plan", false,
+		"print the resources in the generated install plan before approving it")
+
+	fs.BoolVar(&u.CompareWithInstalled, "compare-with-installed", false,
+		"print the API and RBAC changes from the package's installed CSV to the bundle's CSV, "+
+			"failing if owned CRDs are removed unless --confirm is set")
+	fs.BoolVar(&u.Confirm, "confirm", false,
+		"with --compare-with-installed, upgrade to the bundle even if its CSV removes owned CRDs")
+
 	// --mode is hidden so only users who know what they're doing can alter add mode.
 	fs.StringVar((*string)(&u.BundleAddMode), "mode", "", "mode to use for adding new bundle version to index")
 	_ = fs.MarkHidden("mode")
@@ -76,10 +92,21 @@ func (u *Upgrade) setup(ctx context.Context) error {
 	csv := bundle.CSV
 
 	u.OperatorInstaller.PackageName = labels[registrybundle.PackageLabel]
-	u.OperatorInstaller.CatalogSourceName = operator.CatalogNameForPackage(u.OperatorInstaller.PackageName)
+	u.OperatorInstaller.SetCatalogSourceName()
 	u.OperatorInstaller.StartingCSV = csv.Name
 	u.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(csv.Spec.InstallModes)
-	u.OperatorInstaller.Channel = strings.Split(labels[registrybundle.ChannelsLabel], ",")[0]
+	channels, err := operator.GetChannels(labels)
+	if err != nil {
+		return err
+	}
+	u.OperatorInstaller.Channel = channels[0]
+
+	if u.CompareWithInstalled {
+		if err := operator.CheckUpgradeImpact(ctx, u.cfg.Client, os.Stdout, u.cfg.Namespace,
+			u.OperatorInstaller.PackageName, csv, u.Confirm); err != nil {
+			return err
+		}
+	}
 
 	// Since an existing CatalogSource will have an annotation containing the existing index image,
 	// defer defaulting the bundle add mode to after the existing CatalogSource is retrieved.
@@ -0,0 +1,104 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalogpackage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+)
+
+// Install subscribes to a package served by a CatalogSource already in the cluster.
+type Install struct {
+	*registry.ExistingCatalog
+	*registry.OperatorInstaller
+
+	cfg *operator.Configuration
+}
+
+func NewInstall(cfg *operator.Configuration) Install {
+	i := Install{
+		ExistingCatalog:   registry.NewExistingCatalog(cfg),
+		OperatorInstaller: registry.NewOperatorInstaller(cfg),
+		cfg:               cfg,
+	}
+	i.OperatorInstaller.CatalogCreator = i.ExistingCatalog
+	i.OperatorInstaller.Logger = operator.NewRunLogger()
+	return i
+}
+
+func (i *Install) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&i.OperatorInstaller.CatalogSourceName, "catalog-source", "",
+		"name of the CatalogSource serving the package (required)")
+	fs.StringVar(&i.ExistingCatalog.Namespace, "catalog-source-namespace", "",
+		"namespace of the CatalogSource, if not the install namespace")
+	fs.StringVar(&i.OperatorInstaller.Channel, "channel", "",
+		"channel to subscribe to, if not the package's default channel")
+	fs.Var(&i.InstallMode, "install-mode", "install mode")
+	fs.BoolVar(&i.PrintInstallPlan, "print-install-plan", false,
+		"print the resources in the generated install plan before approving it")
+}
+
+func (i Install) Run(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	if err := i.setup(ctx); err != nil {
+		return nil, err
+	}
+	return i.InstallOperator(ctx)
+}
+
+func (i *Install) setup(ctx context.Context) error {
+	if i.OperatorInstaller.CatalogSourceName == "" {
+		return fmt.Errorf("--catalog-source must be set")
+	}
+	if i.ExistingCatalog.Namespace == "" {
+		i.ExistingCatalog.Namespace = i.cfg.Namespace
+	}
+	i.OperatorInstaller.CatalogSourceNamespace = i.ExistingCatalog.Namespace
+
+	// Fail before subscribing if the catalog does not serve the package and channel,
+	// since OLM would otherwise leave the Subscription unresolved.
+	if _, err := i.ExistingCatalog.CreateCatalog(ctx, i.OperatorInstaller.CatalogSourceName); err != nil {
+		return err
+	}
+	pkg, err := registry.GetPackageManifest(ctx, i.cfg.Client, i.OperatorInstaller.PackageName,
+		i.OperatorInstaller.CatalogSourceName, i.ExistingCatalog.Namespace)
+	if err != nil {
+		return err
+	}
+	channel, err := pkg.GetChannel(i.OperatorInstaller.Channel)
+	if err != nil {
+		return fmt.Errorf("package %q: %v", i.OperatorInstaller.PackageName, err)
+	}
+
+	// The package server describes only the channel head's install modes, which is all
+	// CheckCompatibility reads from a CSV.
+	csv := &v1alpha1.ClusterServiceVersion{}
+	csv.SetName(channel.CurrentCSV)
+	csv.Spec.InstallModes = channel.CurrentCSVDesc.InstallModes
+	if err := i.InstallMode.CheckCompatibility(csv, i.cfg.Namespace); err != nil {
+		return err
+	}
+
+	i.OperatorInstaller.Channel = channel.Name
+	i.OperatorInstaller.StartingCSV = channel.CurrentCSV
+	i.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(csv.Spec.InstallModes)
+
+	return i.OperatorInstaller.CheckOperatorGroup(ctx)
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalogpackage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry"
+)
+
+var _ = Describe("Install", func() {
+	var (
+		cfg *operator.Configuration
+		i   Install
+	)
+
+	newPackageManifest := func(name, catalog, namespace string) client.Object {
+		pm := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"defaultChannel": "stable",
+				"channels": []interface{}{
+					map[string]interface{}{
+						"name":       "alpha",
+						"currentCSV": name + ".v0.2.0",
+						"currentCSVDesc": map[string]interface{}{
+							"installModes": []interface{}{
+								map[string]interface{}{"type": "AllNamespaces", "supported": true},
+							},
+						},
+					},
+					map[string]interface{}{
+						"name":       "stable",
+						"currentCSV": name + ".v0.1.0",
+						"currentCSVDesc": map[string]interface{}{
+							"installModes": []interface{}{
+								map[string]interface{}{"type": "OwnNamespace", "supported": true},
+								map[string]interface{}{"type": "AllNamespaces", "supported": false},
+							},
+						},
+					},
+				},
+			},
+		}}
+		pm.SetGroupVersionKind(registry.PackageManifestListGVK.GroupVersion().WithKind("PackageManifest"))
+		pm.SetName(name)
+		pm.SetNamespace(namespace)
+		pm.SetLabels(map[string]string{
+			registry.PackageManifestCatalogLabel:          catalog,
+			registry.PackageManifestCatalogNamespaceLabel: namespace,
+		})
+		return pm
+	}
+
+	newConfig := func() *operator.Configuration {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		// The fake client only stores kinds known to its scheme.
+		sch.AddKnownTypeWithName(registry.PackageManifestListGVK.GroupVersion().WithKind("PackageManifest"), &unstructured.Unstructured{})
+		sch.AddKnownTypeWithName(registry.PackageManifestListGVK, &unstructured.UnstructuredList{})
+		cfg := &operator.Configuration{Namespace: "testns", Scheme: sch}
+		cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			&v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "operatorhubio-catalog", Namespace: "olm"}},
+			newPackageManifest("memcached-operator", "operatorhubio-catalog", "olm"),
+			newPackageManifest("etcd", "other-catalog", "olm"),
+		).Build()
+		return cfg
+	}
+	newTestInstall := func(cfg *operator.Configuration) Install {
+		i := NewInstall(cfg)
+		i.PackageName = "memcached-operator"
+		i.CatalogSourceName = "operatorhubio-catalog"
+		i.ExistingCatalog.Namespace = "olm"
+		return i
+	}
+
+	BeforeEach(func() {
+		cfg = newConfig()
+		i = newTestInstall(cfg)
+	})
+
+	Describe("setup", func() {
+		It("should subscribe to the default channel's head", func() {
+			Expect(i.setup(context.TODO())).To(Succeed())
+			Expect(i.OperatorInstaller.Channel).To(Equal("stable"))
+			Expect(i.StartingCSV).To(Equal("memcached-operator.v0.1.0"))
+			Expect(i.CatalogSourceNamespace).To(Equal("olm"))
+			Expect(i.SupportedInstallModes.List()).To(Equal([]string{"OwnNamespace"}))
+		})
+		It("should subscribe to the requested channel's head", func() {
+			i.OperatorInstaller.Channel = "alpha"
+			Expect(i.setup(context.TODO())).To(Succeed())
+			Expect(i.StartingCSV).To(Equal("memcached-operator.v0.2.0"))
+		})
+		It("should default the catalog source namespace to the install namespace", func() {
+			i.ExistingCatalog.Namespace = ""
+			err := i.setup(context.TODO())
+			Expect(err).To(MatchError(`catalog source "operatorhubio-catalog" not found in namespace "testns"`))
+		})
+		It("should return an error if the catalog does not serve the package", func() {
+			i.PackageName = "etcd"
+			err := i.setup(context.TODO())
+			Expect(err).To(MatchError(`package "etcd" not found in catalog source "operatorhubio-catalog" in namespace "olm"`))
+		})
+		It("should return an error listing channels if the channel does not exist", func() {
+			i.OperatorInstaller.Channel = "fast"
+			err := i.setup(context.TODO())
+			Expect(err).To(MatchError(`package "memcached-operator": channel "fast" not found, available channels: ["alpha" "stable"]`))
+		})
+		It("should return an error if the install mode is not supported", func() {
+			Expect(i.InstallMode.Set("AllNamespaces")).To(Succeed())
+			err := i.setup(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring(`install mode type "AllNamespaces" not supported in CSV "memcached-operator.v0.1.0"`)))
+		})
+		It("should return an error if the catalog source is not set", func() {
+			i.CatalogSourceName = ""
+			Expect(i.setup(context.TODO())).To(MatchError("--catalog-source must be set"))
+		})
+	})
+
+	Describe("Run", func() {
+		It("should tag each concurrent install's logs with its own run ID", func() {
+			hooks := log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+			defer log.StandardLogger().ReplaceHooks(hooks)
+			hook := logtest.NewLocal(log.StandardLogger())
+
+			installs := []Install{newTestInstall(newConfig()), newTestInstall(newConfig())}
+			var wg sync.WaitGroup
+			for j := range installs {
+				wg.Add(1)
+				go func(i *Install) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					// No install plan is generated, so Run fails once the context is done.
+					ctx, cancel := context.WithTimeout(context.TODO(), 500*time.Millisecond)
+					defer cancel()
+					_, err := i.Run(ctx)
+					Expect(err).To(HaveOccurred())
+				}(&installs[j])
+			}
+			wg.Wait()
+
+			ids := map[interface{}]bool{}
+			for _, e := range hook.AllEntries() {
+				ids[e.Data[operator.RunIDField]] = true
+			}
+			Expect(ids).To(HaveLen(2))
+			for _, i := range installs {
+				Expect(ids).To(HaveKey(i.GetLogger().Data[operator.RunIDField]))
+			}
+		})
+	})
+})
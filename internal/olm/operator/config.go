@@ -64,6 +64,15 @@ func (c *Configuration) BindFlags(fs *pflag.FlagSet) {
 		"Duration to wait for the command to complete before failing")
 }
 
+// FlagNamespace returns Namespace if set, otherwise the value of --namespace, without
+// loading the kubeconfig. It returns "" if neither is set.
+func (c *Configuration) FlagNamespace() string {
+	if c.Namespace != "" || c.overrides == nil {
+		return c.Namespace
+	}
+	return c.overrides.Context.Namespace
+}
+
 func (c *Configuration) Load() error {
 	if c.overrides == nil {
 		c.overrides = &clientcmd.ConfigOverrides{}
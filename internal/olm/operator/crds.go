@@ -0,0 +1,135 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// copiedCSVLabel is set by OLM on CSVs it copies into an OperatorGroup's target namespaces.
+const copiedCSVLabel = "olm.copiedFrom"
+
+// CRDConflict is a CRD owned by a bundle's CSV that already exists in the cluster
+// and is owned by another CSV.
+type CRDConflict struct {
+	// CRD is the name of the conflicting CRD.
+	CRD string
+	// CSV is the "namespace/name" of the installed CSV that owns CRD.
+	CSV string
+}
+
+func (c CRDConflict) String() string {
+	return fmt.Sprintf("CRD %q is owned by CSV %q", c.CRD, c.CSV)
+}
+
+// GetCRDConflicts returns the CRDs owned by csv that exist in the cluster and are owned by
+// another operator's CSV, sorted by CRD name. CSVs that csv replaces or skips, and CSVs
+// subscribed to as package pkg, are upgraded by csv and so are not conflicts.
+func GetCRDConflicts(ctx context.Context, c client.Reader, csv *v1alpha1.ClusterServiceVersion, pkg string) ([]CRDConflict, error) {
+	var existing []string
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		crd := apiextv1.CustomResourceDefinition{}
+		err := c.Get(ctx, client.ObjectKey{Name: desc.Name}, &crd)
+		if err == nil {
+			existing = append(existing, desc.Name)
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("get CRD %q: %w", desc.Name, err)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	csvs := v1alpha1.ClusterServiceVersionList{}
+	if err := c.List(ctx, &csvs); err != nil {
+		return nil, fmt.Errorf("list CSVs: %w", err)
+	}
+	upgraded, err := getPackageCSVs(ctx, c, pkg)
+	if err != nil {
+		return nil, err
+	}
+	upgradedNames := sets.NewString(append(csv.Spec.Skips, csv.GetName(), csv.Spec.Replaces)...)
+	var conflicts []CRDConflict
+	for _, name := range existing {
+		for _, installed := range csvs.Items {
+			key := installed.GetNamespace() + "/" + installed.GetName()
+			if upgradedNames.Has(installed.GetName()) || upgraded.Has(key) {
+				continue
+			}
+			if _, ok := installed.GetLabels()[copiedCSVLabel]; ok {
+				continue
+			}
+			for _, desc := range installed.Spec.CustomResourceDefinitions.Owned {
+				if desc.Name == name {
+					conflicts = append(conflicts, CRDConflict{
+						CRD: name,
+						CSV: key,
+					})
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].CRD < conflicts[j].CRD })
+	return conflicts, nil
+}
+
+// getPackageCSVs returns the "namespace/name" of each CSV that a Subscription to pkg has installed
+// or is installing.
+func getPackageCSVs(ctx context.Context, c client.Reader, pkg string) (sets.String, error) {
+	subs := v1alpha1.SubscriptionList{}
+	if err := c.List(ctx, &subs); err != nil {
+		return nil, fmt.Errorf("list subscriptions: %w", err)
+	}
+	csvs := sets.NewString()
+	for _, sub := range subs.Items {
+		if sub.Spec == nil || sub.Spec.Package != pkg {
+			continue
+		}
+		for _, name := range []string{sub.Status.InstalledCSV, sub.Status.CurrentCSV} {
+			if name != "" {
+				csvs.Insert(sub.GetNamespace() + "/" + name)
+			}
+		}
+	}
+	return csvs, nil
+}
+
+// CheckCRDConflicts returns an error describing each CRD owned by csv, of package pkg, that is already
+// owned by another operator's CSV, since OLM will refuse to install csv until they are resolved.
+func CheckCRDConflicts(ctx context.Context, c client.Reader, csv *v1alpha1.ClusterServiceVersion, pkg string) error {
+	conflicts, err := GetCRDConflicts(ctx, c, csv, pkg)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		msgs[i] = conflict.String()
+	}
+	return fmt.Errorf("CSV %q owns CRDs that are already owned by other operators: %s",
+		csv.GetName(), strings.Join(msgs, "; "))
+}
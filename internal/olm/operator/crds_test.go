@@ -0,0 +1,156 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("CRDs", func() {
+	var sch *runtime.Scheme
+
+	BeforeEach(func() {
+		sch = runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		Expect(apiextv1.AddToScheme(sch)).To(Succeed())
+	})
+
+	newCSV := func(namespace, name string, labels map[string]string, crds ...string) *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		}}
+		for _, crd := range crds {
+			csv.Spec.CustomResourceDefinitions.Owned = append(csv.Spec.CustomResourceDefinitions.Owned,
+				v1alpha1.CRDDescription{Name: crd})
+		}
+		return csv
+	}
+	newCRD := func(name string) client.Object {
+		return &apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	csv := newCSV("default", "memcached-operator.v0.0.2", nil,
+		"memcacheds.cache.example.com", "backups.cache.example.com")
+
+	Describe("GetCRDConflicts", func() {
+		It("should return nothing if the bundle's CRDs are not installed", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCSV("other", "other-operator.v1.0.0", nil, "memcacheds.cache.example.com"),
+			).Build()
+			conflicts, err := GetCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+		})
+		It("should ignore CRDs owned by a CSV of the same name and copied CSVs", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+				newCSV("other", "memcached-operator.v0.0.2", nil, "memcacheds.cache.example.com"),
+				newCSV("copy", "other-operator.v1.0.0", map[string]string{"olm.copiedFrom": "other"},
+					"memcacheds.cache.example.com"),
+			).Build()
+			conflicts, err := GetCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+		})
+		It("should ignore CSVs that the CSV replaces or skips", func() {
+			upgrade := csv.DeepCopy()
+			upgrade.Spec.Replaces = "memcached-operator.v0.0.1"
+			upgrade.Spec.Skips = []string{"memcached-operator.v0.0.1-rc.1"}
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+				newCSV("default", "memcached-operator.v0.0.1", nil, "memcacheds.cache.example.com"),
+				newCSV("other", "memcached-operator.v0.0.1-rc.1", nil, "memcacheds.cache.example.com"),
+			).Build()
+			conflicts, err := GetCRDConflicts(context.TODO(), c, upgrade, "memcached-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+		})
+		It("should ignore CSVs installed by a subscription to the same package", func() {
+			sub := &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "memcached-operator"},
+				Spec:       &v1alpha1.SubscriptionSpec{Package: "memcached-operator"},
+				Status:     v1alpha1.SubscriptionStatus{InstalledCSV: "memcached-operator.v0.0.0"},
+			}
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+				newCSV("default", "memcached-operator.v0.0.0", nil, "memcacheds.cache.example.com"),
+				sub,
+			).Build()
+			conflicts, err := GetCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(BeEmpty())
+
+			conflicts, err = GetCRDConflicts(context.TODO(), c, csv, "other-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(HaveLen(1))
+		})
+		It("should return installed CRDs owned by another CSV", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+				newCRD("backups.cache.example.com"),
+				newCSV("other", "other-operator.v1.0.0", nil,
+					"memcacheds.cache.example.com", "backups.cache.example.com"),
+			).Build()
+			conflicts, err := GetCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(conflicts).To(Equal([]CRDConflict{
+				{CRD: "backups.cache.example.com", CSV: "other/other-operator.v1.0.0"},
+				{CRD: "memcacheds.cache.example.com", CSV: "other/other-operator.v1.0.0"},
+			}))
+		})
+	})
+
+	Describe("CheckCRDConflicts", func() {
+		It("should return a Forbidden error if CSVs cannot be listed", func() {
+			c := forbiddenListReader{fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+			).Build()}
+			err := CheckCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+		It("should return an error naming each conflicting CRD and CSV", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCRD("memcacheds.cache.example.com"),
+				newCSV("other", "other-operator.v1.0.0", nil, "memcacheds.cache.example.com"),
+			).Build()
+			err := CheckCRDConflicts(context.TODO(), c, csv, "memcached-operator")
+			Expect(err).To(MatchError(`CSV "memcached-operator.v0.0.2" owns CRDs that are already owned by other operators: ` +
+				`CRD "memcacheds.cache.example.com" is owned by CSV "other/other-operator.v1.0.0"`))
+		})
+	})
+})
+
+// forbiddenListReader returns a Forbidden error from every List.
+type forbiddenListReader struct {
+	client.Reader
+}
+
+func (r forbiddenListReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "clusterserviceversions"}, "", errors.New("no RBAC"))
+}
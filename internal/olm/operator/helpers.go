@@ -16,21 +16,104 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/docker/distribution/reference"
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
 const (
 	SDKOperatorGroupName = "operator-sdk-og"
+
+	// SDKCatalogSourcePublisher is the publisher of CatalogSources created by operator-sdk,
+	// unless overridden by the user.
+	SDKCatalogSourcePublisher = "operator-sdk"
+	// SDKCatalogSourceLabel is set to SDKCatalogSourceLabelValue on every CatalogSource operator-sdk
+	// creates, whatever its publisher and whether or not its registry pod succeeded.
+	SDKCatalogSourceLabel      = "operators.operatorframework.io/created-by"
+	SDKCatalogSourceLabelValue = "operator-sdk"
+	// IndexImageAnnotation holds the base index image tag used to create a catalog.
+	IndexImageAnnotation = "operators.operatorframework.io/index-image"
+	// InjectedBundlesAnnotation holds all bundle image and add mode pairs in the current catalog.
+	InjectedBundlesAnnotation = "operators.operatorframework.io/injected-bundles"
 )
 
+// IsSDKCatalogSource returns true if cs was created by operator-sdk. Catalogs created before
+// SDKCatalogSourceLabel was set are recognized by their default publisher or index image annotations.
+func IsSDKCatalogSource(cs *v1alpha1.CatalogSource) bool {
+	if cs.GetLabels()[SDKCatalogSourceLabel] == SDKCatalogSourceLabelValue {
+		return true
+	}
+	if cs.Spec.Publisher == SDKCatalogSourcePublisher {
+		return true
+	}
+	annotations := cs.GetAnnotations()
+	_, hasIndexImage := annotations[IndexImageAnnotation]
+	_, hasInjectedBundles := annotations[InjectedBundlesAnnotation]
+	return hasIndexImage || hasInjectedBundles
+}
+
+// CatalogNameForPackage returns a CatalogSource name for pkg. Object names are DNS-1123 subdomains,
+// so only characters in pkg that are not allowed in a subdomain are replaced; names derived from
+// valid package names, including dotted ones, are unchanged.
 func CatalogNameForPackage(pkg string) string {
-	return fmt.Sprintf("%s-catalog", pkg)
+	name := fmt.Sprintf("%s-catalog", pkg)
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	sanitized := strings.Trim(invalidSubdomainChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if len(sanitized) > validation.DNS1123SubdomainMaxLength {
+		sanitized = strings.Trim(sanitized[len(sanitized)-validation.DNS1123SubdomainMaxLength:], "-.")
+	}
+	if len(validation.IsDNS1123Subdomain(sanitized)) == 0 {
+		return sanitized
+	}
+	// Dots next to dashes or other dots are still invalid, so fall back to a DNS-1123 label.
+	return k8sutil.TrimDNS1123Label(k8sutil.FormatOperatorNameDNS1123(name))
+}
+
+// invalidSubdomainChars matches characters that are not allowed in a DNS-1123 subdomain.
+var invalidSubdomainChars = regexp.MustCompile(`[^a-z0-9.-]`)
+
+// IsDigestReference returns true if image is referenced by digest
+// (ex. quay.io/foo/bar@sha256:...) rather than by a mutable tag.
+func IsDigestReference(image string) (bool, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false, fmt.Errorf("parse image reference %q: %v", image, err)
+	}
+	_, isDigested := named.(reference.Digested)
+	return isDigested, nil
+}
+
+// GetChannels returns the non-empty, whitespace-trimmed channels declared
+// in a bundle's channels label. An error is returned if no channels are declared.
+func GetChannels(labels registryutil.Labels) ([]string, error) {
+	var channels []string
+	for _, ch := range strings.Split(labels[registrybundle.ChannelsLabel], ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels declared in bundle label %q", registrybundle.ChannelsLabel)
+	}
+	return channels, nil
 }
 
 // LoadBundle returns metadata and manifests from within bundleImage.
@@ -43,6 +126,21 @@ func LoadBundle(ctx context.Context, bundleImage string, skipTLSVerify bool, use
 		_ = os.RemoveAll(bundlePath)
 	}()
 
+	return loadBundleFromDir(bundleImage, bundlePath)
+}
+
+// Default locations of an index image's SQLite database and file-based catalog, from its
+// operators.operatorframework.io.index.database.v1 and operators.operatorframework.io.index.configs.v1 labels.
+var indexImagePaths = []string{
+	filepath.Join("database", "index.db"),
+	"configs",
+}
+
+// loadBundleFromDir returns metadata and manifests from bundlePath, the extracted contents of bundleImage.
+func loadBundleFromDir(bundleImage, bundlePath string) (registryutil.Labels, *apimanifests.Bundle, error) {
+	if isIndexImageDir(bundlePath) {
+		return nil, nil, fmt.Errorf("image %q appears to be an index image, not a bundle; did you mean --index-image?", bundleImage)
+	}
 	labels, _, err := registryutil.FindBundleMetadata(bundlePath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("load bundle metadata: %v", err)
@@ -53,10 +151,87 @@ func LoadBundle(ctx context.Context, bundleImage string, skipTLSVerify bool, use
 		return nil, nil, fmt.Errorf("manifests directory not defined in bundle metadata")
 	}
 	manifestsDir := filepath.Join(bundlePath, relManifestsDir)
+	// GetBundleFromDir panics if no CSV is present, so check for one first.
+	if !containsCSV(manifestsDir) {
+		return nil, nil, fmt.Errorf("bundle image %q contains no ClusterServiceVersion", bundleImage)
+	}
 	bundle, err := apimanifests.GetBundleFromDir(manifestsDir)
 	if err != nil {
 		return nil, nil, fmt.Errorf("load bundle: %v", err)
 	}
+	if bundle == nil || bundle.CSV == nil {
+		return nil, nil, fmt.Errorf("bundle image %q contains no ClusterServiceVersion", bundleImage)
+	}
 
 	return labels, bundle, nil
 }
+
+// isIndexImageDir returns true if dir, the extracted contents of an image, has no bundle metadata
+// at the default path and contains an index database or catalog at their default paths.
+func isIndexImageDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, registrybundle.MetadataDir, registrybundle.AnnotationsFile)); err == nil {
+		return false
+	}
+	for _, path := range indexImagePaths {
+		if _, err := os.Stat(filepath.Join(dir, path)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCSV returns true if any manifest in dir or its subdirectories is a ClusterServiceVersion,
+// matching the files GetBundleFromDir loads.
+func containsCSV(dir string) bool {
+	errFound := errors.New("found ClusterServiceVersion")
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		// Like GetBundleFromDir, skip hidden directories and files.
+		if strings.HasPrefix(info.Name(), ".") && path != dir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		dec := k8syaml.NewYAMLOrJSONDecoder(f, 1024)
+		for {
+			var tm metav1.TypeMeta
+			if err := dec.Decode(&tm); err != nil {
+				return nil
+			}
+			if tm.Kind == v1alpha1.ClusterServiceVersionKind {
+				return errFound
+			}
+		}
+	})
+	return err == errFound
+}
+
+// WriteBundleObjects writes each object in bundle to its own YAML file in dir,
+// creating dir if it does not exist.
+func WriteBundleObjects(dir string, bundle *apimanifests.Bundle) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create bundle extraction directory: %v", err)
+	}
+	for _, obj := range bundle.Objects {
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("marshal %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+		fileName := fmt.Sprintf("%s_%s.yaml", strings.ToLower(obj.GetKind()), obj.GetName())
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), b, 0644); err != nil {
+			return fmt.Errorf("write %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
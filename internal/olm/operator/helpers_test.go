@@ -0,0 +1,242 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	registrybundle "github.com/operator-framework/operator-registry/pkg/lib/bundle"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+var _ = Describe("Helpers", func() {
+
+	Describe("CatalogNameForPackage", func() {
+		It("should not change a valid package name", func() {
+			Expect(CatalogNameForPackage("memcached-operator")).To(Equal("memcached-operator-catalog"))
+		})
+		It("should not change a dotted package name", func() {
+			Expect(CatalogNameForPackage("my.pkg")).To(Equal("my.pkg-catalog"))
+		})
+		It("should replace underscores", func() {
+			Expect(CatalogNameForPackage("example.com_memcached")).To(Equal("example.com-memcached-catalog"))
+		})
+		It("should lowercase the name", func() {
+			Expect(CatalogNameForPackage("MemcachedOperator")).To(Equal("memcachedoperator-catalog"))
+		})
+		It("should replace dots that would still be invalid", func() {
+			Expect(CatalogNameForPackage("my._pkg")).To(Equal("my-pkg-catalog"))
+		})
+		It("should trim names longer than 253 characters", func() {
+			name := CatalogNameForPackage(strings.Repeat("A", 260))
+			Expect(name).To(HaveLen(253))
+			Expect(name).To(HaveSuffix("-catalog"))
+		})
+	})
+
+	Describe("IsDigestReference", func() {
+		It("should return false for a tagged image", func() {
+			isDigest, err := IsDigestReference("quay.io/operator-framework/opm:latest")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isDigest).To(BeFalse())
+		})
+		It("should return false for an image with no tag", func() {
+			isDigest, err := IsDigestReference("quay.io/operator-framework/opm")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isDigest).To(BeFalse())
+		})
+		It("should return true for a digest image", func() {
+			isDigest, err := IsDigestReference("quay.io/operator-framework/opm@sha256:" +
+				"0000000000000000000000000000000000000000000000000000000000000000")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isDigest).To(BeTrue())
+		})
+		It("should return an error for an invalid image reference", func() {
+			_, err := IsDigestReference("Quay.io/INVALID:tag:tag")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("GetChannels", func() {
+		channelsLabels := func(value string) registryutil.Labels {
+			return registryutil.Labels{registrybundle.ChannelsLabel: value}
+		}
+
+		It("should return a single channel", func() {
+			channels, err := GetChannels(channelsLabels("stable"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(channels).To(Equal([]string{"stable"}))
+		})
+		It("should ignore a leading comma", func() {
+			channels, err := GetChannels(channelsLabels(",stable"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(channels).To(Equal([]string{"stable"}))
+		})
+		It("should ignore a trailing comma", func() {
+			channels, err := GetChannels(channelsLabels("stable,"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(channels).To(Equal([]string{"stable"}))
+		})
+		It("should trim whitespace around channels", func() {
+			channels, err := GetChannels(channelsLabels(" stable , beta"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(channels).To(Equal([]string{"stable", "beta"}))
+		})
+		It("should return an error if only commas are declared", func() {
+			_, err := GetChannels(channelsLabels(" , ,"))
+			Expect(err).To(MatchError(ContainSubstring("no channels declared")))
+		})
+		It("should return an error if the label is missing", func() {
+			_, err := GetChannels(registryutil.Labels{})
+			Expect(err).To(MatchError(ContainSubstring("no channels declared")))
+		})
+	})
+	Describe("WriteBundleObjects", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "extract-bundle-")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should write each bundle object to a file", func() {
+			csv := &unstructured.Unstructured{}
+			csv.SetKind("ClusterServiceVersion")
+			csv.SetName("memcached-operator.v0.0.1")
+			crd := &unstructured.Unstructured{}
+			crd.SetKind("CustomResourceDefinition")
+			crd.SetName("memcacheds.cache.example.com")
+			bundle := &apimanifests.Bundle{Objects: []*unstructured.Unstructured{csv, crd}}
+
+			outDir := filepath.Join(dir, "manifests")
+			Expect(WriteBundleObjects(outDir, bundle)).To(Succeed())
+
+			b, err := ioutil.ReadFile(filepath.Join(outDir, "clusterserviceversion_memcached-operator.v0.0.1.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("name: memcached-operator.v0.0.1"))
+			Expect(filepath.Join(outDir, "customresourcedefinition_memcacheds.cache.example.com.yaml")).To(BeAnExistingFile())
+		})
+		It("should return an error if the directory cannot be created", func() {
+			file := filepath.Join(dir, "file")
+			Expect(ioutil.WriteFile(file, []byte{}, 0644)).To(Succeed())
+			err := WriteBundleObjects(filepath.Join(file, "manifests"), &apimanifests.Bundle{})
+			Expect(err).To(MatchError(ContainSubstring("create bundle extraction directory")))
+		})
+	})
+	Describe("loadBundleFromDir", func() {
+		const annotations = `annotations:
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: memcached-operator
+  operators.operatorframework.io.bundle.channels.v1: alpha
+`
+		const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: memcacheds.cache.example.com
+spec:
+  group: cache.example.com
+  names:
+    kind: Memcached
+    plural: memcacheds
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+`
+		const csv = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: memcached-operator.v0.0.1
+spec:
+  installModes:
+  - supported: true
+    type: AllNamespaces
+`
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "bundle-")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dir, "metadata"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "manifests"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "metadata", "annotations.yaml"), []byte(annotations), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should load a bundle containing a CSV", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", "csv.yaml"), []byte(csv), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", "crd.yaml"), []byte(crd), 0644)).To(Succeed())
+			labels, bundle, err := loadBundleFromDir("quay.io/example/memcached-operator-bundle:v0.0.1", dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(labels).To(HaveKeyWithValue(registrybundle.PackageLabel, "memcached-operator"))
+			Expect(bundle.CSV).ToNot(BeNil())
+			Expect(bundle.CSV.GetName()).To(Equal("memcached-operator.v0.0.1"))
+		})
+		It("should load a bundle whose CSV is in a nested manifests directory", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "manifests", "olm"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", "olm", "csv.yaml"), []byte(csv), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", "crd.yaml"), []byte(crd), 0644)).To(Succeed())
+			_, bundle, err := loadBundleFromDir("quay.io/example/memcached-operator-bundle:v0.0.1", dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle.CSV).ToNot(BeNil())
+			Expect(bundle.CSV.GetName()).To(Equal("memcached-operator.v0.0.1"))
+		})
+		It("should return an error if the bundle's only CSV is in a hidden directory", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "manifests", ".olm"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", ".olm", "csv.yaml"), []byte(csv), 0644)).To(Succeed())
+			_, _, err := loadBundleFromDir("quay.io/example/memcached-operator-bundle:v0.0.1", dir)
+			Expect(err).To(MatchError(ContainSubstring("contains no ClusterServiceVersion")))
+		})
+		It("should return an error if the bundle contains no CSV", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "manifests", "crd.yaml"), []byte(crd), 0644)).To(Succeed())
+			_, _, err := loadBundleFromDir("quay.io/example/memcached-operator-bundle:v0.0.1", dir)
+			Expect(err).To(MatchError(`bundle image "quay.io/example/memcached-operator-bundle:v0.0.1" ` +
+				`contains no ClusterServiceVersion`))
+		})
+		It("should return an error if the bundle contains no manifests", func() {
+			_, _, err := loadBundleFromDir("quay.io/example/memcached-operator-bundle:v0.0.1", dir)
+			Expect(err).To(MatchError(ContainSubstring("contains no ClusterServiceVersion")))
+		})
+		It("should return an error suggesting --index-image if the image is an index image", func() {
+			Expect(os.RemoveAll(filepath.Join(dir, "metadata"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "configs", "memcached-operator"), 0755)).To(Succeed())
+			_, _, err := loadBundleFromDir("quay.io/example/memcached-operator-index:v0.0.1", dir)
+			Expect(err).To(MatchError(`image "quay.io/example/memcached-operator-index:v0.0.1" appears to be ` +
+				`an index image, not a bundle; did you mean --index-image?`))
+		})
+		It("should return an error suggesting --index-image if the image has an index database", func() {
+			Expect(os.RemoveAll(filepath.Join(dir, "metadata"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "database"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "database", "index.db"), nil, 0644)).To(Succeed())
+			_, _, err := loadBundleFromDir("quay.io/example/memcached-operator-index:v0.0.1", dir)
+			Expect(err).To(MatchError(ContainSubstring("appears to be an index image")))
+		})
+	})
+})
@@ -15,6 +15,7 @@
 package operator
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"sort"
@@ -43,8 +44,14 @@ func (i *InstallMode) Set(str string) error {
 			i.TargetNamespaces = append(i.TargetNamespaces, strings.TrimSpace(ns))
 		}
 		sort.Strings(i.TargetNamespaces)
-	} else {
-		i.TargetNamespaces = []string{}
+		return i.Validate()
+	}
+	// Target namespaces may be set separately, ex. by --watch-namespaces, so only the type is checked here.
+	// CheckCompatibility validates the complete InstallMode.
+	i.TargetNamespaces = []string{}
+	switch i.InstallModeType {
+	case v1alpha1.InstallModeTypeSingleNamespace, v1alpha1.InstallModeTypeMultiNamespace:
+		return nil
 	}
 	return i.Validate()
 }
@@ -98,20 +105,23 @@ func (i InstallMode) Validate() error {
 }
 
 // CheckCompatibility checks if an InstallMode is compatible with the operator's namespace and is supported by csv.
+// If operatorNamespace is empty, ex. because no cluster was loaded, only csv's support for the InstallMode is checked.
 func (i InstallMode) CheckCompatibility(csv *v1alpha1.ClusterServiceVersion, operatorNamespace string) error {
-	// allnamespace was validated in Validate()
+	if err := i.Validate(); err != nil {
+		return err
+	}
 
 	// own namespace and targetns != opname
-	if i.InstallModeType == v1alpha1.InstallModeTypeOwnNamespace {
+	if i.InstallModeType == v1alpha1.InstallModeTypeOwnNamespace && operatorNamespace != "" {
 		if len(i.TargetNamespaces) > 0 && i.TargetNamespaces[0] != operatorNamespace {
 			return fmt.Errorf("install mode %s must match operator namespace %q", i, operatorNamespace)
 		}
 	}
 
 	// single namespace and targetns == opname
-	if i.InstallModeType == v1alpha1.InstallModeTypeSingleNamespace {
+	if i.InstallModeType == v1alpha1.InstallModeTypeSingleNamespace && operatorNamespace != "" {
 		if len(i.TargetNamespaces) < 1 || i.TargetNamespaces[0] == operatorNamespace {
-			return fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, i.TargetNamespaces[0])
+			return fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, operatorNamespace)
 		}
 	}
 
@@ -123,12 +133,37 @@ func (i InstallMode) CheckCompatibility(csv *v1alpha1.ClusterServiceVersion, ope
 	// ensure the CSV supports the given installmode
 	for _, mode := range csv.Spec.InstallModes {
 		if mode.Type == i.InstallModeType && !mode.Supported {
-			return fmt.Errorf("install mode type %q not supported in CSV %q", i.InstallModeType, csv.GetName())
+			supported := GetSupportedInstallModes(csv.Spec.InstallModes)
+			msg := fmt.Sprintf("install mode type %q not supported in CSV %q; supported install modes: %+q",
+				i.InstallModeType, csv.GetName(), supported.List())
+			if suggestion := suggestInstallMode(supported, operatorNamespace); suggestion != "" {
+				msg = fmt.Sprintf("%s; try --install-mode=%s", msg, suggestion)
+			}
+			return errors.New(msg)
 		}
 	}
 	return nil
 }
 
+// suggestInstallMode returns an --install-mode value for the most permissive
+// mode in supported, in the same order of preference used to create OperatorGroups.
+func suggestInstallMode(supported sets.String, operatorNamespace string) string {
+	switch {
+	case supported.Has(string(v1alpha1.InstallModeTypeAllNamespaces)):
+		return string(v1alpha1.InstallModeTypeAllNamespaces)
+	case supported.Has(string(v1alpha1.InstallModeTypeOwnNamespace)):
+		return string(v1alpha1.InstallModeTypeOwnNamespace)
+	case supported.Has(string(v1alpha1.InstallModeTypeSingleNamespace)):
+		if operatorNamespace == "" {
+			return fmt.Sprintf("%s=<namespace other than the operator's>", v1alpha1.InstallModeTypeSingleNamespace)
+		}
+		return fmt.Sprintf("%s=<namespace other than %s>", v1alpha1.InstallModeTypeSingleNamespace, operatorNamespace)
+	case supported.Has(string(v1alpha1.InstallModeTypeMultiNamespace)):
+		return fmt.Sprintf("%s=<namespace1>,<namespace2>", v1alpha1.InstallModeTypeMultiNamespace)
+	}
+	return ""
+}
+
 // GetSupportedInstallModes returns the given slice of InstallModes as a
 // String set.
 func GetSupportedInstallModes(csvInstallModes []v1alpha1.InstallMode) sets.String {
@@ -63,4 +63,81 @@ var _ = Describe("InstallMode", func() {
 			Expect(supported.Has(string(v1alpha1.InstallModeTypeAllNamespaces))).Should(BeFalse())
 		})
 	})
+	Describe("Set", func() {
+		It("should parse a type and sorted target namespaces", func() {
+			i := InstallMode{}
+			Expect(i.Set("MultiNamespace=ns2, ns1")).To(Succeed())
+			Expect(i).To(Equal(InstallMode{InstallModeType: v1alpha1.InstallModeTypeMultiNamespace, TargetNamespaces: []string{"ns1", "ns2"}}))
+		})
+		It("should accept a namespaced type without target namespaces, to be set later", func() {
+			i := InstallMode{}
+			Expect(i.Set("SingleNamespace")).To(Succeed())
+			Expect(i.TargetNamespaces).To(BeEmpty())
+		})
+		It("should error for an unknown type", func() {
+			i := InstallMode{}
+			Expect(i.Set("SomeNamespaces")).To(MatchError("unknown install mode type"))
+		})
+	})
+
+	Describe("CheckCompatibility", func() {
+		var csv *v1alpha1.ClusterServiceVersion
+		BeforeEach(func() {
+			csv = &v1alpha1.ClusterServiceVersion{}
+			csv.SetName("memcached-operator.v0.0.1")
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: false},
+				{Type: v1alpha1.InstallModeTypeSingleNamespace, Supported: false},
+				{Type: v1alpha1.InstallModeTypeMultiNamespace, Supported: false},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			}
+		})
+
+		It("should succeed if the install mode is supported", func() {
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeAllNamespaces}
+			Expect(i.CheckCompatibility(csv, "testns")).To(Succeed())
+		})
+		It("should check only CSV support if the operator namespace is unknown", func() {
+			csv.Spec.InstallModes[1].Supported = true
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeSingleNamespace, TargetNamespaces: []string{"testns"}}
+			Expect(i.CheckCompatibility(csv, "")).To(Succeed())
+			Expect(i.CheckCompatibility(csv, "testns")).To(MatchError(ContainSubstring(`to watch operator's namespace "testns"`)))
+		})
+		It("should succeed if the install mode is empty", func() {
+			Expect(InstallMode{}.CheckCompatibility(csv, "testns")).To(Succeed())
+		})
+		It("should error if a MultiNamespace install mode has no target namespaces", func() {
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeMultiNamespace}
+			Expect(i.CheckCompatibility(csv, "testns")).To(MatchError(ContainSubstring("must have at least one target namespace")))
+		})
+		It("should list supported modes and suggest AllNamespaces for an AllNamespaces-only operator", func() {
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeOwnNamespace}
+			err := i.CheckCompatibility(csv, "testns")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`supported install modes: ["AllNamespaces"]`))
+			Expect(err.Error()).To(ContainSubstring("try --install-mode=AllNamespaces"))
+		})
+		It("should suggest OwnNamespace for an OwnNamespace-only operator", func() {
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: false},
+			}
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeAllNamespaces}
+			err := i.CheckCompatibility(csv, "testns")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("try --install-mode=OwnNamespace"))
+		})
+		It("should succeed if the install mode is not listed in the CSV", func() {
+			csv.Spec.InstallModes = []v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+			}
+			i := InstallMode{InstallModeType: v1alpha1.InstallModeTypeAllNamespaces}
+			Expect(i.CheckCompatibility(csv, "testns")).To(Succeed())
+		})
+		It("should error if the CSV has no install modes", func() {
+			csv.Spec.InstallModes = nil
+			err := InstallMode{}.CheckCompatibility(csv, "testns")
+			Expect(err).To(MatchError(ContainSubstring("no supported install modes")))
+		})
+	})
 })
@@ -0,0 +1,37 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// RunIDField is the log field holding the ID of the run that wrote a log line.
+const RunIDField = "run"
+
+// NewRunLogger returns an entry of the standard logger tagged with a random run ID,
+// so the logs of concurrent runs can be told apart.
+func NewRunLogger() *log.Entry {
+	return log.WithField(RunIDField, rand.String(5))
+}
+
+// LoggerOrStandard returns logger, or an entry of the standard logger if logger is nil.
+func LoggerOrStandard(logger *log.Entry) *log.Entry {
+	if logger == nil {
+		return log.NewEntry(log.StandardLogger())
+	}
+	return logger
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Pod Security admission levels, see https://kubernetes.io/docs/concepts/security/pod-security-standards/.
+const (
+	PodSecurityLevelPrivileged = "privileged"
+	PodSecurityLevelBaseline   = "baseline"
+	PodSecurityLevelRestricted = "restricted"
+)
+
+// podSecurityLabels are the namespace labels Pod Security admission reads a namespace's level from.
+var podSecurityLabels = []string{
+	"pod-security.kubernetes.io/enforce",
+	"pod-security.kubernetes.io/audit",
+	"pod-security.kubernetes.io/warn",
+}
+
+// ValidatePodSecurityLevel returns an error if level is not a Pod Security admission level.
+func ValidatePodSecurityLevel(level string) error {
+	switch level {
+	case PodSecurityLevelPrivileged, PodSecurityLevelBaseline, PodSecurityLevelRestricted:
+		return nil
+	}
+	return fmt.Errorf("pod security level %q is not one of %q, %q, or %q", level,
+		PodSecurityLevelPrivileged, PodSecurityLevelBaseline, PodSecurityLevelRestricted)
+}
+
+// EnsureNamespace creates namespace name, labeled to enforce, audit, and warn on Pod Security level,
+// if it does not exist. An existing namespace is not modified. EnsureNamespace returns true if
+// it created the namespace.
+func EnsureNamespace(ctx context.Context, c client.Client, name, level string) (bool, error) {
+	ns := corev1.Namespace{}
+	err := c.Get(ctx, client.ObjectKey{Name: name}, &ns)
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("get namespace %q: %v", name, err)
+	}
+
+	if err := c.Create(ctx, NewNamespace(name, level)); err != nil {
+		return false, fmt.Errorf("create namespace %q: %v", name, err)
+	}
+	return true, nil
+}
+
+// NewNamespace returns the namespace name EnsureNamespace creates, labeled to enforce, audit,
+// and warn on Pod Security level.
+func NewNamespace(name, level string) *corev1.Namespace {
+	labels := make(map[string]string, len(podSecurityLabels))
+	for _, label := range podSecurityLabels {
+		labels[label] = level
+	}
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+// GetMissingNamespaces returns the namespaces in names that do not exist.
+func GetMissingNamespaces(ctx context.Context, c client.Client, names []string) ([]string, error) {
+	var missing []string
+	for _, name := range names {
+		err := c.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{})
+		switch {
+		case apierrors.IsNotFound(err):
+			missing = append(missing, name)
+		case err != nil:
+			return nil, fmt.Errorf("get namespace %q: %v", name, err)
+		}
+	}
+	return missing, nil
+}
+
+// GetNamespaceBySelector returns the name of the only namespace matching selector.
+// An error is returned if no namespaces or more than one namespace match.
+func GetNamespaceBySelector(ctx context.Context, c client.Client, selector labels.Selector) (string, error) {
+	nsList := corev1.NamespaceList{}
+	if err := c.List(ctx, &nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", fmt.Errorf("list namespaces: %v", err)
+	}
+	switch len(nsList.Items) {
+	case 0:
+		return "", fmt.Errorf("no namespaces match selector %q", selector)
+	case 1:
+		return nsList.Items[0].GetName(), nil
+	}
+	names := make([]string, len(nsList.Items))
+	for j, ns := range nsList.Items {
+		names[j] = ns.GetName()
+	}
+	return "", fmt.Errorf("%d namespaces match selector %q, expected exactly one: %q", len(names), selector, names)
+}
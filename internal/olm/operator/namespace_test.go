@@ -0,0 +1,105 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Namespace", func() {
+	Describe("ValidatePodSecurityLevel", func() {
+		It("should accept Pod Security levels", func() {
+			Expect(ValidatePodSecurityLevel("privileged")).To(Succeed())
+			Expect(ValidatePodSecurityLevel("baseline")).To(Succeed())
+			Expect(ValidatePodSecurityLevel("restricted")).To(Succeed())
+		})
+		It("should return an error for an unknown level", func() {
+			Expect(ValidatePodSecurityLevel("strict")).To(MatchError(
+				`pod security level "strict" is not one of "privileged", "baseline", or "restricted"`))
+		})
+	})
+
+	Describe("EnsureNamespace", func() {
+		It("should create a labeled namespace if it does not exist", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			created, err := EnsureNamespace(context.TODO(), c, "memcached", "baseline")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeTrue())
+
+			ns := corev1.Namespace{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{Name: "memcached"}, &ns)).To(Succeed())
+			Expect(ns.Labels).To(Equal(map[string]string{
+				"pod-security.kubernetes.io/enforce": "baseline",
+				"pod-security.kubernetes.io/audit":   "baseline",
+				"pod-security.kubernetes.io/warn":    "baseline",
+			}))
+		})
+		It("should not modify an existing namespace", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "memcached"}},
+			).Build()
+			created, err := EnsureNamespace(context.TODO(), c, "memcached", "privileged")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeFalse())
+
+			ns := corev1.Namespace{}
+			Expect(c.Get(context.TODO(), client.ObjectKey{Name: "memcached"}, &ns)).To(Succeed())
+			Expect(ns.Labels).To(BeEmpty())
+		})
+	})
+
+	Describe("GetMissingNamespaces", func() {
+		It("should return only the namespaces that do not exist", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+			).Build()
+			missing, err := GetMissingNamespaces(context.TODO(), c, []string{"ns1", "ns2", "ns3"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(missing).To(Equal([]string{"ns2", "ns3"}))
+		})
+	})
+
+	Describe("GetNamespaceBySelector", func() {
+		var c client.Client
+		BeforeEach(func() {
+			c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"env": "pr-1", "team": "a"}}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"env": "pr-2", "team": "a"}}},
+			).Build()
+		})
+		It("should return the only matching namespace", func() {
+			ns, err := GetNamespaceBySelector(context.TODO(), c, labels.SelectorFromSet(labels.Set{"env": "pr-1"}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ns).To(Equal("ns1"))
+		})
+		It("should return an error if no namespaces match", func() {
+			_, err := GetNamespaceBySelector(context.TODO(), c, labels.SelectorFromSet(labels.Set{"env": "pr-3"}))
+			Expect(err).To(MatchError(`no namespaces match selector "env=pr-3"`))
+		})
+		It("should return an error if more than one namespace matches", func() {
+			_, err := GetNamespaceBySelector(context.TODO(), c, labels.SelectorFromSet(labels.Set{"team": "a"}))
+			Expect(err).To(MatchError(`2 namespaces match selector "team=a", expected exactly one: ["ns1" "ns2"]`))
+		})
+	})
+})
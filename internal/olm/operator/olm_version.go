@@ -0,0 +1,102 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	olmclient "github.com/operator-framework/operator-sdk/internal/olm/client"
+)
+
+// olmNamespaces are the namespaces upstream OLM and OpenShift install OLM in.
+var olmNamespaces = []string{"olm", "openshift-operator-lifecycle-manager"}
+
+// olmFeature is a bundle feature that requires a minimum OLM version.
+type olmFeature struct {
+	name       string
+	minVersion semver.Version
+	usedBy     func(*apimanifests.Bundle) bool
+}
+
+var olmFeatures = []olmFeature{
+	{
+		name:       "admission webhooks (spec.webhookdefinitions)",
+		minVersion: semver.MustParse("0.15.0"),
+		usedBy: func(b *apimanifests.Bundle) bool {
+			return hasWebhookType(b.CSV, v1alpha1.ValidatingAdmissionWebhook, v1alpha1.MutatingAdmissionWebhook)
+		},
+	},
+	{
+		name:       "conversion webhooks (spec.webhookdefinitions)",
+		minVersion: semver.MustParse("0.16.0"),
+		usedBy: func(b *apimanifests.Bundle) bool {
+			return hasWebhookType(b.CSV, v1alpha1.ConversionWebhook)
+		},
+	},
+}
+
+func hasWebhookType(csv *v1alpha1.ClusterServiceVersion, types ...v1alpha1.WebhookAdmissionType) bool {
+	for _, wh := range csv.Spec.WebhookDefinitions {
+		for _, t := range types {
+			if wh.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetOLMVersion returns the version of OLM installed in the cluster, checking each namespace OLM
+// is commonly installed in.
+func GetOLMVersion(ctx context.Context, c client.Client) (string, error) {
+	olmClient := olmclient.Client{KubeClient: c}
+	for _, ns := range olmNamespaces {
+		version, err := olmClient.GetInstalledVersion(ctx, ns)
+		if err == nil {
+			return version, nil
+		}
+		if !errors.Is(err, olmclient.ErrOLMNotInstalled) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("OLM not found in namespaces %+q", olmNamespaces)
+}
+
+// CheckOLMVersionSupport returns an error describing each feature used by bundle that
+// olmVersion does not support.
+func CheckOLMVersionSupport(bundle *apimanifests.Bundle, olmVersion string) error {
+	version, err := semver.ParseTolerant(olmVersion)
+	if err != nil {
+		return fmt.Errorf("invalid OLM version %q: %v", olmVersion, err)
+	}
+	var unsupported []string
+	for _, feature := range olmFeatures {
+		if feature.usedBy(bundle) && version.LT(feature.minVersion) {
+			unsupported = append(unsupported, fmt.Sprintf("%s requires OLM %s", feature.name, feature.minVersion))
+		}
+	}
+	if len(unsupported) != 0 {
+		return fmt.Errorf("bundle uses features not supported by OLM %s: %s", version, strings.Join(unsupported, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("OLM version", func() {
+	Describe("GetOLMVersion", func() {
+		var sch *runtime.Scheme
+		BeforeEach(func() {
+			sch = runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		})
+
+		It("should return the version from the OpenShift OLM namespace", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(&v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "packageserver",
+					Namespace: "openshift-operator-lifecycle-manager",
+					Labels:    map[string]string{"olm.version": "0.19.0"},
+				},
+			}).Build()
+			Expect(GetOLMVersion(context.TODO(), c)).To(Equal("0.19.0"))
+		})
+		It("should return an error if OLM is not installed", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).Build()
+			_, err := GetOLMVersion(context.TODO(), c)
+			Expect(err).To(MatchError(`OLM not found in namespaces ["olm" "openshift-operator-lifecycle-manager"]`))
+		})
+	})
+
+	Describe("CheckOLMVersionSupport", func() {
+		var bundle *apimanifests.Bundle
+		BeforeEach(func() {
+			bundle = &apimanifests.Bundle{CSV: &v1alpha1.ClusterServiceVersion{}}
+		})
+
+		It("should succeed if the bundle uses no versioned features", func() {
+			Expect(CheckOLMVersionSupport(bundle, "0.10.0")).To(Succeed())
+		})
+		It("should succeed if OLM supports the bundle's features", func() {
+			bundle.CSV.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{{Type: v1alpha1.ConversionWebhook}}
+			Expect(CheckOLMVersionSupport(bundle, "v0.16.0")).To(Succeed())
+		})
+		It("should describe each feature OLM does not support", func() {
+			bundle.CSV.Spec.WebhookDefinitions = []v1alpha1.WebhookDescription{
+				{Type: v1alpha1.ValidatingAdmissionWebhook},
+				{Type: v1alpha1.ConversionWebhook},
+			}
+			err := CheckOLMVersionSupport(bundle, "0.14.2")
+			Expect(err).To(MatchError("bundle uses features not supported by OLM 0.14.2: " +
+				"admission webhooks (spec.webhookdefinitions) requires OLM 0.15.0; " +
+				"conversion webhooks (spec.webhookdefinitions) requires OLM 0.16.0"))
+		})
+		It("should return an error for an invalid version", func() {
+			Expect(CheckOLMVersionSupport(bundle, "latest")).To(MatchError(ContainSubstring(`invalid OLM version "latest"`)))
+		})
+	})
+})
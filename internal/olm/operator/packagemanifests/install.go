@@ -44,6 +44,9 @@ func NewInstall(cfg *operator.Configuration) Install {
 		cfg:                     cfg,
 	}
 	i.OperatorInstaller.CatalogCreator = i.ConfigMapCatalogCreator
+	logger := operator.NewRunLogger()
+	i.OperatorInstaller.Logger = logger
+	i.ConfigMapCatalogCreator.Logger = logger
 	return i
 }
 
@@ -74,7 +77,7 @@ func (i *Install) setup() error {
 	}
 
 	i.OperatorInstaller.PackageName = pkg.PackageName
-	i.OperatorInstaller.CatalogSourceName = operator.CatalogNameForPackage(i.OperatorInstaller.PackageName)
+	i.OperatorInstaller.SetCatalogSourceName()
 	i.OperatorInstaller.StartingCSV = bundle.CSV.GetName()
 	i.OperatorInstaller.SupportedInstallModes = operator.GetSupportedInstallModes(bundle.CSV.Spec.InstallModes)
 
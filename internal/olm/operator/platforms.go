@@ -0,0 +1,102 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	csvArchLabelPrefix = "operatorframework.io/arch."
+	csvOSLabelPrefix   = "operatorframework.io/os."
+	csvLabelSupported  = "supported"
+
+	// OLM assumes these platforms when a CSV has no arch or os labels.
+	defaultCSVArch = "amd64"
+	defaultCSVOS   = "linux"
+)
+
+// GetSupportedPlatforms returns the sorted "os/arch" platforms a CSV supports
+// according to its operatorframework.io/arch.* and operatorframework.io/os.* labels.
+func GetSupportedPlatforms(csv *v1alpha1.ClusterServiceVersion) []string {
+	var arches, oses []string
+	for k, v := range csv.GetLabels() {
+		if v != csvLabelSupported {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(k, csvArchLabelPrefix):
+			arches = append(arches, strings.TrimPrefix(k, csvArchLabelPrefix))
+		case strings.HasPrefix(k, csvOSLabelPrefix):
+			oses = append(oses, strings.TrimPrefix(k, csvOSLabelPrefix))
+		}
+	}
+	if len(arches) == 0 {
+		arches = []string{defaultCSVArch}
+	}
+	if len(oses) == 0 {
+		oses = []string{defaultCSVOS}
+	}
+
+	var platforms []string
+	for _, osName := range oses {
+		for _, arch := range arches {
+			platforms = append(platforms, osName+"/"+arch)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// GetNodePlatforms returns the sorted, unique "os/arch" platforms of all nodes in the cluster.
+func GetNodePlatforms(ctx context.Context, c client.Reader) ([]string, error) {
+	nodes := corev1.NodeList{}
+	if err := c.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	seen := map[string]struct{}{}
+	var platforms []string
+	for _, node := range nodes.Items {
+		platform := node.Labels[corev1.LabelOSStable] + "/" + node.Labels[corev1.LabelArchStable]
+		if _, ok := seen[platform]; !ok {
+			seen[platform] = struct{}{}
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// CheckPlatformCompatibility returns an error if none of nodePlatforms, from GetNodePlatforms,
+// is supported by the CSV, since the operator's pods would never be scheduled.
+func CheckPlatformCompatibility(csv *v1alpha1.ClusterServiceVersion, nodePlatforms []string) error {
+	supported := GetSupportedPlatforms(csv)
+	for _, platform := range nodePlatforms {
+		for _, s := range supported {
+			if platform == s {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no nodes match the platforms supported by CSV %q; supported platforms: %+q; node platforms: %+q",
+		csv.GetName(), supported, nodePlatforms)
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Platforms", func() {
+	newNode := func(name, os, arch string) client.Object {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{corev1.LabelOSStable: os, corev1.LabelArchStable: arch},
+		}}
+	}
+
+	Describe("GetSupportedPlatforms", func() {
+		It("should default to linux/amd64 if the CSV has no platform labels", func() {
+			csv := &v1alpha1.ClusterServiceVersion{}
+			Expect(GetSupportedPlatforms(csv)).To(Equal([]string{"linux/amd64"}))
+		})
+		It("should return every supported os and arch combination", func() {
+			csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+				"operatorframework.io/arch.arm64":   "supported",
+				"operatorframework.io/arch.s390x":   "supported",
+				"operatorframework.io/arch.ppc64le": "unsupported",
+				"operatorframework.io/os.linux":     "supported",
+			}}}
+			Expect(GetSupportedPlatforms(csv)).To(Equal([]string{"linux/arm64", "linux/s390x"}))
+		})
+	})
+
+	Describe("GetNodePlatforms", func() {
+		It("should return the unique platforms of all nodes", func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				newNode("node-1", "linux", "arm64"),
+				newNode("node-2", "linux", "amd64"),
+				newNode("node-3", "linux", "arm64"),
+			).Build()
+			Expect(GetNodePlatforms(context.TODO(), c)).To(Equal([]string{"linux/amd64", "linux/arm64"}))
+		})
+	})
+
+	Describe("CheckPlatformCompatibility", func() {
+		csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator.v0.0.1"}}
+
+		It("should succeed if a node has a supported platform", func() {
+			Expect(CheckPlatformCompatibility(csv, []string{"linux/amd64", "linux/arm64"})).To(Succeed())
+		})
+		It("should return an error listing platforms if no node has a supported platform", func() {
+			err := CheckPlatformCompatibility(csv, []string{"linux/arm64"})
+			Expect(err).To(MatchError(`no nodes match the platforms supported by CSV "memcached-operator.v0.0.1"; ` +
+				`supported platforms: ["linux/amd64"]; node platforms: ["linux/arm64"]`))
+		})
+	})
+})
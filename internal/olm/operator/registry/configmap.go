@@ -33,6 +33,8 @@ import (
 type ConfigMapCatalogCreator struct {
 	Package *apimanifests.PackageManifest
 	Bundles []*apimanifests.Bundle
+	// Logger, if set, is used instead of the standard logger.
+	Logger *log.Entry
 
 	cfg *operator.Configuration
 }
@@ -46,7 +48,7 @@ func NewConfigMapCatalogCreator(cfg *operator.Configuration) *ConfigMapCatalogCr
 func (c ConfigMapCatalogCreator) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
 	cs := newCatalogSource(name, c.cfg.Namespace,
 		withSDKPublisher(c.Package.PackageName))
-	if err := c.cfg.Client.Create(ctx, cs); err != nil {
+	if err := createWithRetry(ctx, c.cfg.Client, cs); err != nil {
 		return nil, fmt.Errorf("error creating catalog source: %w", err)
 	}
 
@@ -75,10 +77,10 @@ func (c ConfigMapCatalogCreator) registryUp(ctx context.Context, cs *v1alpha1.Ca
 	} else if exists {
 		if isRegistryStale, err := rr.IsRegistryDataStale(ctx, c.cfg.Namespace); err == nil {
 			if !isRegistryStale {
-				log.Infof("%s registry data is current", c.Package.PackageName)
+				operator.LoggerOrStandard(c.Logger).Infof("%s registry data is current", c.Package.PackageName)
 				return nil
 			}
-			log.Infof("A stale %s registry exists, deleting", c.Package.PackageName)
+			operator.LoggerOrStandard(c.Logger).Infof("A stale %s registry exists, deleting", c.Package.PackageName)
 			if err = rr.DeletePackageManifestsRegistry(ctx, c.cfg.Namespace); err != nil {
 				return fmt.Errorf("error deleting registered package: %w", err)
 			}
@@ -86,7 +88,7 @@ func (c ConfigMapCatalogCreator) registryUp(ctx context.Context, cs *v1alpha1.Ca
 			return fmt.Errorf("error checking registry data: %w", err)
 		}
 	}
-	log.Infof("Creating %s registry", c.Package.PackageName)
+	operator.LoggerOrStandard(c.Logger).Infof("Creating %s registry", c.Package.PackageName)
 	if err := rr.CreatePackageManifestsRegistry(ctx, cs, c.cfg.Namespace); err != nil {
 		return fmt.Errorf("error registering package: %w", err)
 	}
@@ -0,0 +1,52 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+// ExistingCatalog is a CatalogCreator that returns a CatalogSource already in the cluster
+// instead of creating one, so an operator can be installed from a catalog the SDK did not create.
+type ExistingCatalog struct {
+	// Namespace is the namespace of the CatalogSource.
+	Namespace string
+
+	cfg *operator.Configuration
+}
+
+func NewExistingCatalog(cfg *operator.Configuration) *ExistingCatalog {
+	return &ExistingCatalog{cfg: cfg}
+}
+
+// CreateCatalog returns the CatalogSource name in Namespace.
+func (c ExistingCatalog) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
+	cs := &v1alpha1.CatalogSource{}
+	key := client.ObjectKey{Namespace: c.Namespace, Name: name}
+	if err := c.cfg.Client.Get(ctx, key, cs); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("catalog source %q not found in namespace %q", name, c.Namespace)
+		}
+		return nil, fmt.Errorf("get catalog source %q: %v", name, err)
+	}
+	return cs, nil
+}
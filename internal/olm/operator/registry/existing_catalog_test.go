@@ -0,0 +1,56 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("ExistingCatalog", func() {
+	var (
+		cfg *operator.Configuration
+		c   *ExistingCatalog
+	)
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		cfg = &operator.Configuration{Namespace: "testns", Scheme: sch}
+		cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+			newCatalogSource("operatorhubio-catalog", "olm"),
+		).Build()
+		c = NewExistingCatalog(cfg)
+	})
+
+	It("should return the existing catalog source", func() {
+		c.Namespace = "olm"
+		cs, err := c.CreateCatalog(context.TODO(), "operatorhubio-catalog")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cs.GetName()).To(Equal("operatorhubio-catalog"))
+		Expect(cs.GetNamespace()).To(Equal("olm"))
+	})
+	It("should return an error if the catalog source does not exist", func() {
+		c.Namespace = "testns"
+		_, err := c.CreateCatalog(context.TODO(), "operatorhubio-catalog")
+		Expect(err).To(MatchError(`catalog source "operatorhubio-catalog" not found in namespace "testns"`))
+	})
+})
@@ -0,0 +1,127 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultLogTailLines is the number of log lines printed for each of a failed registry pod's
+// containers, unless debug logging is enabled, in which case all lines are printed.
+const defaultLogTailLines int64 = 10
+
+// podLogGetter returns the logs of a pod's container, limited to the last tailLines lines if tailLines is set.
+type podLogGetter func(ctx context.Context, pod *corev1.Pod, container string, tailLines *int64) ([]byte, error)
+
+// newPodLogGetter returns a podLogGetter that reads logs with a clientset built from cfg.
+func newPodLogGetter(cfg *rest.Config) (podLogGetter, error) {
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, pod *corev1.Pod, container string, tailLines *int64) ([]byte, error) {
+		opts := &corev1.PodLogOptions{Container: container, TailLines: tailLines}
+		stream, err := cs.CoreV1().Pods(pod.GetNamespace()).GetLogs(pod.GetName(), opts).Stream(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.Close()
+		buf := &bytes.Buffer{}
+		_, err = io.Copy(buf, stream)
+		return buf.Bytes(), err
+	}, nil
+}
+
+// writePodDiagnostics writes pod's status, events, and container logs to w, so a registry pod
+// that fails to run can be diagnosed. Errors getting events or logs are written in their place.
+func writePodDiagnostics(ctx context.Context, w io.Writer, c client.Reader, getLogs podLogGetter, pod *corev1.Pod) {
+	fmt.Fprintf(w, "Registry pod %s/%s is in phase %q", pod.GetNamespace(), pod.GetName(), pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(w, ", reason %q", pod.Status.Reason)
+	}
+	if pod.Status.Message != "" {
+		fmt.Fprintf(w, ": %s", pod.Status.Message)
+	}
+	fmt.Fprintln(w)
+	for _, status := range pod.Status.ContainerStatuses {
+		switch state := status.State; {
+		case state.Waiting != nil:
+			fmt.Fprintf(w, "  container %s is waiting: %s %s\n", status.Name, state.Waiting.Reason, state.Waiting.Message)
+		case state.Terminated != nil:
+			fmt.Fprintf(w, "  container %s terminated with exit code %d: %s %s\n", status.Name,
+				state.Terminated.ExitCode, state.Terminated.Reason, state.Terminated.Message)
+		}
+	}
+
+	events := corev1.EventList{}
+	if err := c.List(ctx, &events, client.InNamespace(pod.GetNamespace())); err != nil {
+		fmt.Fprintf(w, "Failed to list events: %v\n", err)
+	} else {
+		writePodEvents(w, pod, events.Items)
+	}
+
+	if getLogs == nil {
+		return
+	}
+	tailLines := defaultLogTailLines
+	tail := &tailLines
+	if log.IsLevelEnabled(log.DebugLevel) {
+		tail = nil
+	}
+	for _, container := range pod.Spec.Containers {
+		logs, err := getLogs(ctx, pod, container.Name, tail)
+		if err != nil {
+			fmt.Fprintf(w, "Failed to get logs of container %s: %v\n", container.Name, err)
+			continue
+		}
+		if tail != nil {
+			fmt.Fprintf(w, "Last %d log lines of container %s (use --verbose for all lines):\n", tailLines, container.Name)
+		} else {
+			fmt.Fprintf(w, "Logs of container %s:\n", container.Name)
+		}
+		fmt.Fprintln(w, strings.TrimRight(string(logs), "\n"))
+	}
+}
+
+// writePodEvents writes the events in events involving pod, oldest first.
+func writePodEvents(w io.Writer, pod *corev1.Pod, events []corev1.Event) {
+	var podEvents []corev1.Event
+	for _, e := range events {
+		if e.InvolvedObject.Kind == "Pod" && e.InvolvedObject.Name == pod.GetName() {
+			podEvents = append(podEvents, e)
+		}
+	}
+	if len(podEvents) == 0 {
+		return
+	}
+	sort.SliceStable(podEvents, func(i, j int) bool {
+		return podEvents[i].LastTimestamp.Before(&podEvents[j].LastTimestamp)
+	})
+	fmt.Fprintln(w, "Events:")
+	for _, e := range podEvents {
+		fmt.Fprintf(w, "  %s %s: %s\n", e.Type, e.Reason, e.Message)
+	}
+}
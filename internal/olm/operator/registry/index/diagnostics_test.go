@@ -0,0 +1,114 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("writePodDiagnostics", func() {
+	var pod *corev1.Pod
+
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "quay-io-example-bundle-0-0-1", Namespace: "testns"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: defaultContainerName}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: defaultContainerName,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image",
+					}},
+				}},
+			},
+		}
+	})
+
+	newEvent := func(name, objName, reason string, ts time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "testns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: objName},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " message",
+			LastTimestamp:  metav1.NewTime(ts),
+		}
+	}
+
+	It("should write the pod's status, events, and last log lines", func() {
+		now := time.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			newEvent("e2", pod.GetName(), "BackOff", now),
+			newEvent("e1", pod.GetName(), "Failed", now.Add(-time.Minute)),
+			newEvent("e3", "other-pod", "Killing", now),
+		).Build()
+		var gotTail *int64
+		getLogs := func(_ context.Context, _ *corev1.Pod, container string, tailLines *int64) ([]byte, error) {
+			gotTail = tailLines
+			return []byte("line 1\nline 2\n"), nil
+		}
+
+		w := &bytes.Buffer{}
+		writePodDiagnostics(context.TODO(), w, c, getLogs, pod)
+		Expect(w.String()).To(Equal(`Registry pod testns/quay-io-example-bundle-0-0-1 is in phase "Pending"
+  container registry-grpc is waiting: ImagePullBackOff Back-off pulling image
+Events:
+  Warning Failed: Failed message
+  Warning BackOff: BackOff message
+Last 10 log lines of container registry-grpc (use --verbose for all lines):
+line 1
+line 2
+`))
+		Expect(*gotTail).To(Equal(defaultLogTailLines))
+	})
+
+	It("should get all log lines if debug logging is enabled", func() {
+		level := log.GetLevel()
+		log.SetLevel(log.DebugLevel)
+		defer log.SetLevel(level)
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		getLogs := func(_ context.Context, _ *corev1.Pod, _ string, tailLines *int64) ([]byte, error) {
+			Expect(tailLines).To(BeNil())
+			return []byte("line 1\n"), nil
+		}
+		w := &bytes.Buffer{}
+		writePodDiagnostics(context.TODO(), w, c, getLogs, pod)
+		Expect(w.String()).To(ContainSubstring("Logs of container registry-grpc:\nline 1\n"))
+	})
+
+	It("should write log errors in place of logs", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		getLogs := func(context.Context, *corev1.Pod, string, *int64) ([]byte, error) {
+			return nil, errors.New("container is waiting to start")
+		}
+		w := &bytes.Buffer{}
+		writePodDiagnostics(context.TODO(), w, c, getLogs, pod)
+		Expect(w.String()).To(ContainSubstring("Failed to get logs of container registry-grpc: container is waiting to start"))
+	})
+})
@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"text/template"
 	"time"
@@ -83,6 +84,16 @@ type RegistryPod struct { //nolint:maligned
 	// UseHTTP uses plain HTTP for container image registries while pulling bundles.
 	UseHTTP bool `json:"UseHTTP"`
 
+	// SecurityContextConfig is the security context the pod runs with. Defaults to legacy.
+	SecurityContextConfig SecurityContextConfig
+
+	// ImagePullPolicy is the pull policy of the index image container.
+	// Defaults to the kubelet's default for the image's tag.
+	ImagePullPolicy corev1.PullPolicy
+
+	// Logger, if set, is used instead of the standard logger.
+	Logger *log.Entry
+
 	// pod represents a kubernetes *corev1.pod that will be created on a cluster using an index image
 	pod *corev1.Pod
 
@@ -148,12 +159,29 @@ func (rp *RegistryPod) Create(ctx context.Context, cfg *operator.Configuration,
 
 	// check pod status to be `Running`
 	if err := rp.checkPodStatus(ctx, podCheck); err != nil {
+		rp.reportFailure()
 		return nil, fmt.Errorf("registry pod did not become ready: %w", err)
 	}
-	log.Infof("Successfully created registry pod: %s", rp.pod.Name)
+	operator.LoggerOrStandard(rp.Logger).Infof("Successfully created registry pod: %s", rp.pod.Name)
 	return rp.pod, nil
 }
 
+// reportFailure writes the diagnostics of a registry pod that did not become ready to stderr.
+func (rp *RegistryPod) reportFailure() {
+	// The install's context is likely done, so use a new one.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var getLogs podLogGetter
+	if rp.cfg.RESTConfig != nil {
+		var err error
+		if getLogs, err = newPodLogGetter(rp.cfg.RESTConfig); err != nil {
+			operator.LoggerOrStandard(rp.Logger).Debugf("Failed to create client to get registry pod logs: %v", err)
+		}
+	}
+	writePodDiagnostics(ctx, os.Stderr, rp.cfg.Client, getLogs, rp.pod)
+}
+
 // checkPodStatus polls and verifies that the pod status is running
 func (rp *RegistryPod) checkPodStatus(ctx context.Context, podCheck wait.ConditionFunc) error {
 	// poll every 200 ms until podCheck is true or context is done
@@ -184,15 +212,55 @@ func (rp *RegistryPod) validate() error {
 		return errors.New("index image cannot be empty")
 	}
 
+	if err := rp.SecurityContextConfig.Validate(); err != nil {
+		return err
+	}
+
+	if err := ValidatePullPolicy(rp.ImagePullPolicy); err != nil {
+		return err
+	}
+
+	if err := ValidateGRPCPort(rp.GRPCPort); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func GetRegistryPodHost(ipStr string) string {
-	return fmt.Sprintf("%s:%d", ipStr, defaultGRPCPort)
+// ValidatePullPolicy returns an error if policy is set and is not a valid image pull policy.
+func ValidatePullPolicy(policy corev1.PullPolicy) error {
+	switch policy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return nil
+	}
+	return fmt.Errorf("image pull policy %q is not one of %q, %q, or %q",
+		policy, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+}
+
+// ValidateGRPCPort returns an error if port is set and is not a valid port number.
+func ValidateGRPCPort(port int32) error {
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("grpc port %d is not between 1 and 65535", port)
+	}
+	return nil
+}
+
+// GetRegistryPodHost returns the address of pod's registry server, using the port of its grpc
+// container port, or the default grpc port for pods created before the port was configurable.
+func GetRegistryPodHost(pod *corev1.Pod) string {
+	port := int32(defaultGRPCPort)
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == defaultContainerPortName {
+				port = p.ContainerPort
+			}
+		}
+	}
+	return fmt.Sprintf("%s:%d", pod.Status.PodIP, port)
 }
 
-// getPodName will return a string constructed from the bundle Image name
-func getPodName(bundleImage string) string {
+// GetPodName will return a string constructed from the bundle Image name
+func GetPodName(bundleImage string) string {
 	// todo(rashmigottipati): need to come up with human-readable references
 	// to be able to handle SHA references in the bundle images
 	return k8sutil.TrimDNS1123Label(k8sutil.FormatOperatorNameDNS1123(bundleImage))
@@ -213,14 +281,15 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 	// make the pod definition
 	rp.pod = &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getPodName(bundleImage),
+			Name:      GetPodName(bundleImage),
 			Namespace: rp.cfg.Namespace,
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
-					Name:  defaultContainerName,
-					Image: rp.IndexImage,
+					Name:            defaultContainerName,
+					Image:           rp.IndexImage,
+					ImagePullPolicy: rp.ImagePullPolicy,
 					Command: []string{
 						"sh",
 						"-c",
@@ -237,6 +306,7 @@ func (rp *RegistryPod) podForBundleRegistry() (*corev1.Pod, error) {
 
 	addImagePullSecret(rp.pod, rp.SecretName)
 	addCertSecret(rp.pod, rp.CASecretName)
+	rp.SecurityContextConfig.apply(rp.pod)
 
 	return rp.pod, nil
 }
@@ -87,6 +87,30 @@ var _ = Describe("RegistryPod", func() {
 				}
 			})
 
+			It("should not set a security context by default", func() {
+				Expect(rp.pod.Spec.SecurityContext).To(BeNil())
+				Expect(rp.pod.Spec.Containers[0].SecurityContext).To(BeNil())
+			})
+
+			It("should set a restricted security context", func() {
+				rp.SecurityContextConfig = RestrictedSecurityContextConfig
+				pod, err := rp.podForBundleRegistry()
+				Expect(err).To(BeNil())
+				Expect(*pod.Spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+				Expect(pod.Spec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+				for _, container := range pod.Spec.Containers {
+					Expect(*container.SecurityContext.AllowPrivilegeEscalation).To(BeFalse())
+					Expect(container.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+				}
+			})
+
+			It("should set the index image pull policy", func() {
+				rp.ImagePullPolicy = corev1.PullAlways
+				pod, err := rp.podForBundleRegistry()
+				Expect(err).To(BeNil())
+				Expect(pod.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+			})
+
 			It("should create a registry pod when database path is not provided", func() {
 				Expect(rp.DBPath).To(Equal("/database/index.db"))
 			})
@@ -258,6 +282,26 @@ var _ = Describe("RegistryPod", func() {
 				Expect(err.Error()).Should(ContainSubstring(expectedErr))
 			})
 
+			It("should not accept an unknown security context config", func() {
+				rp := &RegistryPod{
+					BundleItems:           defaultBundleItems,
+					IndexImage:            testIndexImageTag,
+					SecurityContextConfig: "privileged",
+				}
+				err := rp.init(cfg)
+				Expect(err).To(MatchError(ContainSubstring(`security context config "privileged" does not exist`)))
+			})
+
+			It("should not accept an unknown image pull policy", func() {
+				rp := &RegistryPod{
+					BundleItems:     defaultBundleItems,
+					IndexImage:      testIndexImageTag,
+					ImagePullPolicy: "Sometimes",
+				}
+				err := rp.init(cfg)
+				Expect(err).To(MatchError(ContainSubstring(`image pull policy "Sometimes" is not one of "Always", "IfNotPresent", or "Never"`)))
+			})
+
 			It("checkPodStatus should return error when pod check is false and context is done", func() {
 				rp := &RegistryPod{
 					BundleItems: defaultBundleItems,
@@ -278,6 +322,35 @@ var _ = Describe("RegistryPod", func() {
 				Expect(err).NotTo(BeNil())
 				Expect(err.Error()).Should(ContainSubstring(expectedErr))
 			})
+
+			It("should not accept an out of range grpc port", func() {
+				rp := &RegistryPod{
+					BundleItems: defaultBundleItems,
+					IndexImage:  testIndexImageTag,
+					GRPCPort:    70000,
+				}
+				err := rp.init(cfg)
+				Expect(err).To(MatchError(ContainSubstring("grpc port 70000 is not between 1 and 65535")))
+			})
+		})
+	})
+
+	Describe("GetRegistryPodHost", func() {
+		It("should use the pod's grpc container port", func() {
+			rp := &RegistryPod{
+				BundleItems: defaultBundleItems,
+				IndexImage:  testIndexImageTag,
+				GRPCPort:    50052,
+			}
+			Expect(rp.init(&operator.Configuration{Namespace: "test-default"})).To(Succeed())
+			pod, err := rp.podForBundleRegistry()
+			Expect(err).To(BeNil())
+			pod.Status.PodIP = "10.0.0.1"
+			Expect(GetRegistryPodHost(pod)).To(Equal("10.0.0.1:50052"))
+		})
+		It("should default to the default grpc port", func() {
+			pod := &corev1.Pod{Status: corev1.PodStatus{PodIP: "10.0.0.1"}}
+			Expect(GetRegistryPodHost(pod)).To(Equal("10.0.0.1:50051"))
 		})
 	})
 })
@@ -0,0 +1,63 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecurityContextConfig is the security context a registry pod runs with.
+type SecurityContextConfig string
+
+const (
+	// LegacySecurityContextConfig sets no security context, so the registry pod runs as the index image's user.
+	LegacySecurityContextConfig SecurityContextConfig = "legacy"
+	// RestrictedSecurityContextConfig runs the registry pod with a security context that satisfies
+	// the "restricted" Pod Security Standard. The index image must run as a non-root user
+	// that can write to the index database.
+	RestrictedSecurityContextConfig SecurityContextConfig = "restricted"
+)
+
+var securityContextConfigs = []SecurityContextConfig{LegacySecurityContextConfig, RestrictedSecurityContextConfig}
+
+// Validate returns an error if c is not a known security context config. An empty config is legacy.
+func (c SecurityContextConfig) Validate() error {
+	switch c {
+	case "", LegacySecurityContextConfig, RestrictedSecurityContextConfig:
+		return nil
+	default:
+		return fmt.Errorf("security context config %q does not exist, must be one of: %+q", c, securityContextConfigs)
+	}
+}
+
+// apply sets c's security context on pod and its containers.
+func (c SecurityContextConfig) apply(pod *corev1.Pod) {
+	if c != RestrictedSecurityContextConfig {
+		return
+	}
+	runAsNonRoot, allowPrivilegeEscalation := true, false
+	pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+		RunAsNonRoot:   &runAsNonRoot,
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].SecurityContext = &corev1.SecurityContext{
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		}
+	}
+}
@@ -28,7 +28,10 @@ import (
 	gofunk "github.com/thoas/go-funk"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 
@@ -51,9 +54,9 @@ const (
 	operatorFrameworkGroup = "operators.operatorframework.io"
 
 	// Holds the base index image tag used to create a catalog.
-	indexImageAnnotation = operatorFrameworkGroup + "/index-image"
+	indexImageAnnotation = operator.IndexImageAnnotation
 	// Holds all bundle image and add mode pairs in the current catalog.
-	injectedBundlesAnnotation = operatorFrameworkGroup + "/injected-bundles"
+	injectedBundlesAnnotation = operator.InjectedBundlesAnnotation
 	// Holds the name of the existing registry pod associated with a catalog.
 	registryPodNameAnnotation = operatorFrameworkGroup + "/registry-pod-name"
 )
@@ -68,6 +71,31 @@ type IndexImageCatalogCreator struct {
 	BundleAddMode index.BundleAddMode
 	SecretName    string
 	CASecretName  string
+	// DisplayName and Publisher are set on a created CatalogSource's spec.
+	// They default to PackageName and "operator-sdk" respectively.
+	DisplayName string
+	Publisher   string
+	// AdditionalBundleImages are added to a created catalog, in order, before BundleImage.
+	AdditionalBundleImages []string
+	// Labels and Annotations are added to a created CatalogSource's metadata.
+	Labels      map[string]string
+	Annotations map[string]string
+	// InheritCatalogConfig is the name of an existing CatalogSource in the target namespace
+	// whose priority, display metadata, and secrets are copied to a created CatalogSource.
+	InheritCatalogConfig string
+	// SecurityContextConfig is the security context registry pods run with.
+	SecurityContextConfig index.SecurityContextConfig
+	// CatalogPullPolicy is the pull policy of registry pods' index image container.
+	CatalogPullPolicy corev1.PullPolicy
+	// GRPCPort is the port registry pods serve the catalog on. Defaults to 50051.
+	GRPCPort int32
+	// CatalogSourceTemplate, if set, is a partial CatalogSource whose labels, annotations, display metadata,
+	// priority, and secrets a created CatalogSource starts from. Fields set by other options override the template's.
+	CatalogSourceTemplate *v1alpha1.CatalogSource
+	// Patches are applied to a created CatalogSource before it is created.
+	Patches []ResourcePatch
+	// Logger, if set, is used instead of the standard logger.
+	Logger *log.Entry
 
 	cfg *operator.Configuration
 }
@@ -99,23 +127,34 @@ func (c *IndexImageCatalogCreator) BindFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&c.SkipTLSVerify, "skip-tls-verify", false, "skip TLS certificate verification for container image registries "+
 		"while pulling bundles")
+	fs.StringVar((*string)(&c.SecurityContextConfig), "security-context-config", string(index.LegacySecurityContextConfig),
+		"security context the registry pod runs with, one of \"legacy\" or \"restricted\". "+
+			"\"restricted\" satisfies the restricted Pod Security Standard and requires an index image that runs as a non-root user")
+	fs.StringVar((*string)(&c.CatalogPullPolicy), "catalog-pull-policy", "",
+		"image pull policy of the registry pod's index image, one of \"Always\", \"IfNotPresent\", or \"Never\". "+
+			"Set \"Always\" to pick up a rebuilt index image pushed to the same tag")
+	fs.Int32Var(&c.GRPCPort, "catalog-grpc-port", 0,
+		"port the registry pod serves the catalog on, if not the default 50051")
 	fs.BoolVar(&c.UseHTTP, "use-http", false, "use plain HTTP for container image registries "+
 		"while pulling bundles")
 }
 
 func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
-	// Create a CatalogSource with displaName, publisher, and any secrets.
-	cs := newCatalogSource(name, c.cfg.Namespace,
-		withSDKPublisher(c.PackageName),
-		withSecrets(c.SecretName),
-	)
-	if err := c.cfg.Client.Create(ctx, cs); err != nil {
+	cs, err := c.buildCatalogSource(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := createWithRetry(ctx, c.cfg.Client, cs); err != nil {
 		return nil, fmt.Errorf("error creating catalog source: %v", err)
 	}
 
 	c.setAddMode()
 
-	newItems := []index.BundleItem{{ImageTag: c.BundleImage, AddMode: c.BundleAddMode}}
+	var newItems []index.BundleItem
+	for _, image := range c.AdditionalBundleImages {
+		newItems = append(newItems, index.BundleItem{ImageTag: image, AddMode: c.BundleAddMode})
+	}
+	newItems = append(newItems, index.BundleItem{ImageTag: c.BundleImage, AddMode: c.BundleAddMode})
 	if err := c.createAnnotatedRegistry(ctx, cs, newItems); err != nil {
 		return nil, fmt.Errorf("error creating registry pod: %v", err)
 	}
@@ -123,6 +162,110 @@ func (c IndexImageCatalogCreator) CreateCatalog(ctx context.Context, name string
 	return cs, nil
 }
 
+// buildCatalogSource returns the CatalogSource named name that CreateCatalog creates, before
+// its registry pod is added.
+func (c IndexImageCatalogCreator) buildCatalogSource(ctx context.Context, name string) (*v1alpha1.CatalogSource, error) {
+	if tmpl := c.CatalogSourceTemplate; tmpl != nil {
+		if tmpl.GetName() != "" && tmpl.GetName() != name {
+			return nil, fmt.Errorf("catalog source template name %q does not match catalog source name %q", tmpl.GetName(), name)
+		}
+		if fields := ignoredCatalogSourceFields(tmpl.Spec); len(fields) != 0 {
+			operator.LoggerOrStandard(c.Logger).Warnf("Ignoring %s of the catalog source template, "+
+				"which only apply to catalogs whose registry pod OLM creates", strings.Join(fields, " and "))
+		}
+	}
+
+	opts := []func(*v1alpha1.CatalogSource){
+		withSDKPublisher(c.PackageName),
+		withTemplate(c.CatalogSourceTemplate),
+	}
+	if c.InheritCatalogConfig != "" {
+		src, err := c.getInheritedCatalog(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if fields := ignoredCatalogSourceFields(src.Spec); len(fields) != 0 {
+			operator.LoggerOrStandard(c.Logger).Warnf("Not inheriting %s of catalog source %q, "+
+				"which only apply to catalogs whose registry pod OLM creates", strings.Join(fields, " and "), src.GetName())
+		}
+		opts = append(opts, withInheritedConfig(src))
+	}
+	opts = append(opts,
+		withDisplayNamePublisher(c.DisplayName, c.Publisher),
+		withLabels(c.Labels),
+		withAnnotations(c.Annotations),
+		withSecrets(c.SecretName))
+
+	// Create a CatalogSource with displaName, publisher, and any secrets.
+	cs := newCatalogSource(name, c.cfg.Namespace, opts...)
+	if err := patchCatalogSource(cs, c.Patches); err != nil {
+		return nil, err
+	}
+	// Neither the template nor the inherited catalog sets these fields, so only a patch can have.
+	if fields := ignoredCatalogSourceFields(cs.Spec); len(fields) != 0 {
+		operator.LoggerOrStandard(c.Logger).Warnf("Catalog source patches set %s, which have no effect because they "+
+			"only apply to catalogs whose registry pod OLM creates", strings.Join(fields, " and "))
+	}
+	// The template, inherited catalog, pull secret flag, and patches may name the same secret.
+	cs.Spec.Secrets = gofunk.UniqString(cs.Spec.Secrets)
+	return cs, nil
+}
+
+// getInheritedCatalog gets the CatalogSource named by InheritCatalogConfig in the target namespace.
+func (c IndexImageCatalogCreator) getInheritedCatalog(ctx context.Context) (*v1alpha1.CatalogSource, error) {
+	src := &v1alpha1.CatalogSource{}
+	key := types.NamespacedName{Namespace: c.cfg.Namespace, Name: c.InheritCatalogConfig}
+	if err := c.cfg.Client.Get(ctx, key, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("catalog source %q to inherit config from not found in namespace %q",
+				c.InheritCatalogConfig, c.cfg.Namespace)
+		}
+		return nil, fmt.Errorf("error getting catalog source %q to inherit config from: %v", c.InheritCatalogConfig, err)
+	}
+	return src, nil
+}
+
+// ValidateMetadata returns an error if Labels or Annotations are not valid Kubernetes
+// metadata, or if Annotations sets a key reserved for tracking injected bundles.
+func (c IndexImageCatalogCreator) ValidateMetadata() error {
+	if errs := metav1validation.ValidateLabels(c.Labels, field.NewPath("metadata", "labels")); len(errs) != 0 {
+		return fmt.Errorf("invalid catalog source labels: %v", errs.ToAggregate())
+	}
+	if errs := apivalidation.ValidateAnnotations(c.Annotations, field.NewPath("metadata", "annotations")); len(errs) != 0 {
+		return fmt.Errorf("invalid catalog source annotations: %v", errs.ToAggregate())
+	}
+	for _, key := range []string{indexImageAnnotation, injectedBundlesAnnotation, registryPodNameAnnotation} {
+		if _, hasKey := c.Annotations[key]; hasKey {
+			return fmt.Errorf("catalog source annotation %q is reserved", key)
+		}
+	}
+	return nil
+}
+
+// ValidateTemplate returns an error if CatalogSourceTemplate sets fields that conflict with
+// a created CatalogSource's generated namespace, source, or reserved annotations.
+func (c IndexImageCatalogCreator) ValidateTemplate() error {
+	tmpl := c.CatalogSourceTemplate
+	if tmpl == nil {
+		return nil
+	}
+	if ns := tmpl.GetNamespace(); ns != "" && ns != c.cfg.Namespace {
+		return fmt.Errorf("catalog source template namespace %q does not match namespace %q", ns, c.cfg.Namespace)
+	}
+	if tmpl.Spec.SourceType != "" && tmpl.Spec.SourceType != v1alpha1.SourceTypeGrpc {
+		return fmt.Errorf("catalog source template sourceType must be empty or %q", v1alpha1.SourceTypeGrpc)
+	}
+	if tmpl.Spec.Image != "" || tmpl.Spec.Address != "" || tmpl.Spec.ConfigMap != "" {
+		return errors.New("catalog source template must not set image, address, or configMap, which are generated")
+	}
+	t := c
+	t.Labels, t.Annotations = tmpl.GetLabels(), tmpl.GetAnnotations()
+	if err := t.ValidateMetadata(); err != nil {
+		return fmt.Errorf("invalid catalog source template: %v", err)
+	}
+	return nil
+}
+
 // UpdateCatalog links a new registry pod in catalog source by updating the address and annotations,
 // then deletes existing registry pod based on annotation name found in catalog source object
 func (c IndexImageCatalogCreator) UpdateCatalog(ctx context.Context, cs *v1alpha1.CatalogSource) error {
@@ -168,7 +311,7 @@ func (c IndexImageCatalogCreator) UpdateCatalog(ctx context.Context, cs *v1alpha
 		return fmt.Errorf("error creating registry: %v", err)
 	}
 
-	log.Infof("Updated catalog source %s with address and annotations", cs.GetName())
+	operator.LoggerOrStandard(c.Logger).Infof("Updated catalog source %s with address and annotations", cs.GetName())
 
 	if prevRegistryPodName != "" {
 		if err = c.deleteRegistryPod(ctx, prevRegistryPodName); err != nil {
@@ -179,6 +322,13 @@ func (c IndexImageCatalogCreator) UpdateCatalog(ctx context.Context, cs *v1alpha
 	return nil
 }
 
+// EffectiveBundleAddMode returns the mode used to add bundles to the index,
+// defaulted from IndexImage if BundleAddMode is unset.
+func (c IndexImageCatalogCreator) EffectiveBundleAddMode() index.BundleAddMode {
+	c.setAddMode()
+	return c.BundleAddMode
+}
+
 // Default add mode here since it depends on an existing annotation.
 // TODO(v2.0.0): this should default to semver mode.
 func (c *IndexImageCatalogCreator) setAddMode() {
@@ -207,6 +357,11 @@ func (c IndexImageCatalogCreator) createAnnotatedRegistry(ctx context.Context, c
 		CASecretName:  c.CASecretName,
 		SkipTLSVerify: c.SkipTLSVerify,
 		UseHTTP:       c.UseHTTP,
+
+		SecurityContextConfig: c.SecurityContextConfig,
+		ImagePullPolicy:       c.CatalogPullPolicy,
+		GRPCPort:              c.GRPCPort,
+		Logger:                c.Logger,
 	}
 	if registryPod.DBPath, err = c.getDBPath(ctx); err != nil {
 		return fmt.Errorf("get database path: %v", err)
@@ -260,7 +415,7 @@ func (c IndexImageCatalogCreator) getDBPath(ctx context.Context) (string, error)
 // and overwrites all annotations with keys matching those in newAnnotations.
 func updateCatalogSourceFields(cs *v1alpha1.CatalogSource, targetPod *corev1.Pod, newAnnotations map[string]string) {
 	// set `spec.Address` and `spec.SourceType` as grpc
-	cs.Spec.Address = index.GetRegistryPodHost(targetPod.Status.PodIP)
+	cs.Spec.Address = index.GetRegistryPodHost(targetPod)
 	cs.Spec.SourceType = v1alpha1.SourceTypeGrpc
 
 	// set annotations
@@ -312,7 +467,7 @@ func (c IndexImageCatalogCreator) deleteRegistryPod(ctx context.Context, podName
 	if err := c.cfg.Client.Delete(ctx, &pod); err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("delete %q: %v", pod.GetName(), err)
 	} else if err == nil {
-		log.Infof("Deleted previous registry pod with name %q", pod.GetName())
+		operator.LoggerOrStandard(c.Logger).Infof("Deleted previous registry pod with name %q", pod.GetName())
 	}
 
 	// Failure of the old pod to clean up should block and cause the caller to error out if it fails,
@@ -0,0 +1,211 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("IndexImageCatalogCreator", func() {
+	Describe("ValidateMetadata", func() {
+		var c *IndexImageCatalogCreator
+		BeforeEach(func() {
+			c = NewIndexImageCatalogCreator(&operator.Configuration{})
+		})
+
+		It("should succeed with valid labels and annotations", func() {
+			c.Labels = map[string]string{"app.kubernetes.io/managed-by": "gitops"}
+			c.Annotations = map[string]string{"example.com/owner": "team a"}
+			Expect(c.ValidateMetadata()).To(Succeed())
+		})
+		It("should return an error for an invalid label key", func() {
+			c.Labels = map[string]string{"bad key": "value"}
+			Expect(c.ValidateMetadata()).To(MatchError(ContainSubstring("invalid catalog source labels")))
+		})
+		It("should return an error for an invalid label value", func() {
+			c.Labels = map[string]string{"key": "bad value"}
+			Expect(c.ValidateMetadata()).To(MatchError(ContainSubstring("invalid catalog source labels")))
+		})
+		It("should return an error for an invalid annotation key", func() {
+			c.Annotations = map[string]string{"bad key": "value"}
+			Expect(c.ValidateMetadata()).To(MatchError(ContainSubstring("invalid catalog source annotations")))
+		})
+		It("should return an error for a reserved annotation key", func() {
+			c.Annotations = map[string]string{injectedBundlesAnnotation: "[]"}
+			Expect(c.ValidateMetadata()).To(MatchError(ContainSubstring("is reserved")))
+		})
+	})
+
+	Describe("getInheritedCatalog", func() {
+		var c *IndexImageCatalogCreator
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			cfg := &operator.Configuration{Namespace: "fakeNS"}
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCatalogSource("existing", "fakeNS", withSecrets("pull-secret")),
+			).Build()
+			c = NewIndexImageCatalogCreator(cfg)
+		})
+
+		It("should get an existing catalog source", func() {
+			c.InheritCatalogConfig = "existing"
+			cs, err := c.getInheritedCatalog(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Secrets).To(Equal([]string{"pull-secret"}))
+		})
+		It("should return an error if the catalog source does not exist", func() {
+			c.InheritCatalogConfig = "missing"
+			_, err := c.getInheritedCatalog(context.TODO())
+			Expect(err).To(MatchError(`catalog source "missing" to inherit config from not found in namespace "fakeNS"`))
+		})
+	})
+
+	Describe("buildCatalogSource", func() {
+		var c *IndexImageCatalogCreator
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			cfg := &operator.Configuration{Namespace: "fakeNS"}
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newCatalogSource("existing", "fakeNS", withSecrets("pull-secret")),
+			).Build()
+			c = NewIndexImageCatalogCreator(cfg)
+		})
+
+		It("should warn about fields it does not inherit, and let flags override inherited fields", func() {
+			src := newCatalogSource("polling", "fakeNS")
+			src.Spec.Publisher = "Example"
+			src.Spec.UpdateStrategy = &v1alpha1.UpdateStrategy{RegistryPoll: &v1alpha1.RegistryPoll{RawInterval: "10m"}}
+			Expect(c.cfg.Client.Create(context.TODO(), src)).To(Succeed())
+			logger, hook := logtest.NewNullLogger()
+			c.Logger = log.NewEntry(logger)
+			c.InheritCatalogConfig = "polling"
+			c.Publisher = "My Team"
+
+			cs, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Publisher).To(Equal("My Team"))
+			Expect(cs.Spec.UpdateStrategy).To(BeNil())
+			Expect(hook.LastEntry()).ToNot(BeNil())
+			Expect(hook.LastEntry().Level).To(Equal(log.WarnLevel))
+			Expect(hook.LastEntry().Message).To(ContainSubstring(`Not inheriting spec.updateStrategy of catalog source "polling"`))
+		})
+		It("should keep the template's priority if the inherited catalog has none", func() {
+			c.CatalogSourceTemplate = newCatalogSource("", "")
+			c.CatalogSourceTemplate.Spec.Priority = 5
+			c.InheritCatalogConfig = "existing"
+
+			cs, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Priority).To(Equal(5))
+			Expect(cs.Spec.Secrets).To(ContainElement("pull-secret"))
+		})
+		It("should list each secret from the template, inherited catalog, and flag once", func() {
+			c.CatalogSourceTemplate = newCatalogSource("", "", withSecrets("template-secret", "pull-secret"))
+			c.InheritCatalogConfig = "existing"
+			c.SecretName = "template-secret"
+
+			cs, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Secrets).To(Equal([]string{"template-secret", "pull-secret"}))
+		})
+		It("should label the catalog as created by operator-sdk before its registry pod is added", func() {
+			c.CatalogSourceTemplate = newCatalogSource("", "")
+			c.CatalogSourceTemplate.SetLabels(map[string]string{operator.SDKCatalogSourceLabel: "gitops"})
+			c.CatalogSourceTemplate.Spec.Publisher = "Template Team"
+			c.Publisher = "My Team"
+
+			cs, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Publisher).To(Equal("My Team"))
+			Expect(cs.GetAnnotations()).ToNot(HaveKey(indexImageAnnotation))
+			Expect(cs.GetLabels()).To(HaveKeyWithValue(operator.SDKCatalogSourceLabel, operator.SDKCatalogSourceLabelValue))
+			Expect(operator.IsSDKCatalogSource(cs)).To(BeTrue())
+		})
+		It("should warn about patched fields that do not apply", func() {
+			c.Patches = []ResourcePatch{{
+				Kind:  v1alpha1.CatalogSourceKind,
+				Patch: json.RawMessage(`[{"op": "add", "path": "/spec/updateStrategy", "value": {"registryPoll": {"interval": "10m"}}}]`),
+			}}
+			logger, hook := logtest.NewNullLogger()
+			c.Logger = log.NewEntry(logger)
+
+			_, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hook.LastEntry()).ToNot(BeNil())
+			Expect(hook.LastEntry().Level).To(Equal(log.WarnLevel))
+			Expect(hook.LastEntry().Message).To(ContainSubstring("Catalog source patches set spec.updateStrategy, which have no effect"))
+		})
+		It("should warn about and ignore template fields that do not apply", func() {
+			c.CatalogSourceTemplate = newCatalogSource("", "")
+			c.CatalogSourceTemplate.Spec.Priority = 5
+			c.CatalogSourceTemplate.Spec.GrpcPodConfig = &v1alpha1.GrpcPodConfig{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}
+			logger, hook := logtest.NewNullLogger()
+			c.Logger = log.NewEntry(logger)
+
+			cs, err := c.buildCatalogSource(context.TODO(), "fakeName")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cs.Spec.Priority).To(Equal(5))
+			Expect(cs.Spec.GrpcPodConfig).To(BeNil())
+			Expect(hook.LastEntry()).ToNot(BeNil())
+			Expect(hook.LastEntry().Message).To(ContainSubstring("Ignoring spec.grpcPodConfig of the catalog source template"))
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		var c *IndexImageCatalogCreator
+		BeforeEach(func() {
+			c = NewIndexImageCatalogCreator(&operator.Configuration{Namespace: "fakeNS"})
+			c.CatalogSourceTemplate = newCatalogSource("", "", withLabels(map[string]string{"team": "a"}))
+		})
+
+		It("should succeed with no template", func() {
+			c.CatalogSourceTemplate = nil
+			Expect(c.ValidateTemplate()).To(Succeed())
+		})
+		It("should succeed with a valid template", func() {
+			c.CatalogSourceTemplate.Spec.SourceType = v1alpha1.SourceTypeGrpc
+			Expect(c.ValidateTemplate()).To(Succeed())
+		})
+		It("should return an error for a different namespace", func() {
+			c.CatalogSourceTemplate.SetNamespace("otherNS")
+			Expect(c.ValidateTemplate()).To(MatchError(ContainSubstring(`namespace "otherNS" does not match`)))
+		})
+		It("should return an error for a non-grpc source type", func() {
+			c.CatalogSourceTemplate.Spec.SourceType = v1alpha1.SourceTypeConfigmap
+			Expect(c.ValidateTemplate()).To(MatchError(ContainSubstring("sourceType must be empty")))
+		})
+		It("should return an error for a generated field", func() {
+			c.CatalogSourceTemplate.Spec.Image = "quay.io/example/index:latest"
+			Expect(c.ValidateTemplate()).To(MatchError(ContainSubstring("must not set image, address, or configMap")))
+		})
+		It("should return an error for a reserved annotation", func() {
+			withAnnotations(map[string]string{registryPodNameAnnotation: "pod"})(c.CatalogSourceTemplate)
+			Expect(c.ValidateTemplate()).To(MatchError(ContainSubstring("is reserved")))
+		})
+	})
+})
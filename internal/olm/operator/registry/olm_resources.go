@@ -77,7 +77,54 @@ func newSubscription(csvName, namespace string, opts ...func(*v1alpha1.Subscript
 func withSDKPublisher(pkgName string) func(*v1alpha1.CatalogSource) {
 	return func(cs *v1alpha1.CatalogSource) {
 		cs.Spec.DisplayName = pkgName
-		cs.Spec.Publisher = "operator-sdk"
+		cs.Spec.Publisher = operator.SDKCatalogSourcePublisher
+	}
+}
+
+// withDisplayNamePublisher returns a function that overrides a CatalogSource's
+// display name and publisher with displayName and publisher, if they are non-empty.
+func withDisplayNamePublisher(displayName, publisher string) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		if displayName != "" {
+			cs.Spec.DisplayName = displayName
+		}
+		if publisher != "" {
+			cs.Spec.Publisher = publisher
+		}
+	}
+}
+
+// withLabels adds labels to a CatalogSource's labels.
+func withLabels(labels map[string]string) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		if len(labels) == 0 {
+			return
+		}
+		l := cs.GetLabels()
+		if l == nil {
+			l = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			l[k] = v
+		}
+		cs.SetLabels(l)
+	}
+}
+
+// withAnnotations adds annotations to a CatalogSource's annotations.
+func withAnnotations(annotations map[string]string) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		if len(annotations) == 0 {
+			return
+		}
+		a := cs.GetAnnotations()
+		if a == nil {
+			a = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			a[k] = v
+		}
+		cs.SetAnnotations(a)
 	}
 }
 
@@ -89,6 +136,73 @@ func withSecrets(secretNames ...string) func(*v1alpha1.CatalogSource) {
 	}
 }
 
+// withTemplate returns a function that sets a CatalogSource's labels, annotations, and
+// non-empty display metadata, priority, and secrets to those of tmpl, if tmpl is not nil.
+// Fields in ignoredCatalogSourceFields are not copied.
+func withTemplate(tmpl *v1alpha1.CatalogSource) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		if tmpl == nil {
+			return
+		}
+		withLabels(tmpl.GetLabels())(cs)
+		withAnnotations(tmpl.GetAnnotations())(cs)
+		spec := tmpl.Spec.DeepCopy()
+		if spec.DisplayName != "" {
+			cs.Spec.DisplayName = spec.DisplayName
+		}
+		if spec.Publisher != "" {
+			cs.Spec.Publisher = spec.Publisher
+		}
+		if spec.Description != "" {
+			cs.Spec.Description = spec.Description
+		}
+		if spec.Icon != (v1alpha1.Icon{}) {
+			cs.Spec.Icon = spec.Icon
+		}
+		if spec.Priority != 0 {
+			cs.Spec.Priority = spec.Priority
+		}
+		cs.Spec.Secrets = append(cs.Spec.Secrets, spec.Secrets...)
+	}
+}
+
+// withInheritedConfig copies src's non-zero priority, non-empty display metadata, and secrets to a CatalogSource.
+// Fields in ignoredCatalogSourceFields are not copied.
+func withInheritedConfig(src *v1alpha1.CatalogSource) func(*v1alpha1.CatalogSource) {
+	return func(cs *v1alpha1.CatalogSource) {
+		if src.Spec.Priority != 0 {
+			cs.Spec.Priority = src.Spec.Priority
+		}
+		if src.Spec.DisplayName != "" {
+			cs.Spec.DisplayName = src.Spec.DisplayName
+		}
+		if src.Spec.Publisher != "" {
+			cs.Spec.Publisher = src.Spec.Publisher
+		}
+		if src.Spec.Description != "" {
+			cs.Spec.Description = src.Spec.Description
+		}
+		if src.Spec.Icon != (v1alpha1.Icon{}) {
+			cs.Spec.Icon = src.Spec.Icon
+		}
+		cs.Spec.Secrets = append(cs.Spec.Secrets, src.Spec.Secrets...)
+	}
+}
+
+// ignoredCatalogSourceFields returns the fields set in spec that OLM only applies to a catalog whose
+// registry pod it creates from spec.image. A catalog created by operator-sdk is served by its own
+// registry pod at spec.address, so these fields would have no effect on it.
+func ignoredCatalogSourceFields(spec v1alpha1.CatalogSourceSpec) []string {
+	var fields []string
+	if spec.UpdateStrategy != nil {
+		fields = append(fields, "spec.updateStrategy")
+	}
+	if spec.GrpcPodConfig != nil {
+		fields = append(fields, "spec.grpcPodConfig")
+	}
+	return fields
+}
+
 // newCatalogSource creates a new CatalogSource with a name derived from
 // pkgName, the package manifest's packageName, in namespace. opts will
 // be applied to the CatalogSource object.
@@ -100,6 +214,8 @@ func newCatalogSource(name, namespace string, opts ...func(*v1alpha1.CatalogSour
 	for _, opt := range opts {
 		opt(cs)
 	}
+	// Label cs after opts so user labels cannot remove the marker cleanup relies on.
+	withLabels(map[string]string{operator.SDKCatalogSourceLabel: operator.SDKCatalogSourceLabelValue})(cs)
 	return cs
 }
 
@@ -17,6 +17,7 @@ package registry
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 )
 
 var _ = Describe("newCatalogSource", func() {
@@ -34,6 +35,87 @@ var _ = Describe("newCatalogSource", func() {
 			Expect(cs.Spec.Publisher).To(Equal("operator-sdk"))
 		})
 	})
+	Describe("withDisplayNamePublisher", func() {
+		It("should override the display name and publisher of a CatalogSource", func() {
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"),
+				withDisplayNamePublisher("My Catalog", "My Org"))
+			Expect(cs.Spec.DisplayName).To(Equal("My Catalog"))
+			Expect(cs.Spec.Publisher).To(Equal("My Org"))
+		})
+		It("should not override the display name and publisher with empty values", func() {
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"),
+				withDisplayNamePublisher("", ""))
+			Expect(cs.Spec.DisplayName).To(Equal("fakeDisplay"))
+			Expect(cs.Spec.Publisher).To(Equal("operator-sdk"))
+		})
+	})
+	Describe("withLabels", func() {
+		It("should add labels to a CatalogSource", func() {
+			cs := newCatalogSource("fakeName", "fakeNS",
+				withLabels(map[string]string{"app.kubernetes.io/managed-by": "gitops"}))
+			Expect(cs.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "gitops"))
+		})
+	})
+	Describe("withAnnotations", func() {
+		It("should add annotations to a CatalogSource", func() {
+			cs := newCatalogSource("fakeName", "fakeNS",
+				withAnnotations(map[string]string{"example.com/owner": "team-a"}))
+			Expect(cs.GetAnnotations()).To(HaveKeyWithValue("example.com/owner", "team-a"))
+		})
+	})
+	Describe("withTemplate", func() {
+		It("should not change a CatalogSource if the template is nil", func() {
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"), withTemplate(nil))
+			Expect(cs.Spec.DisplayName).To(Equal("fakeDisplay"))
+		})
+		It("should set the template's metadata and non-empty spec fields", func() {
+			tmpl := newCatalogSource("", "",
+				withLabels(map[string]string{"team": "a"}),
+				withAnnotations(map[string]string{"example.com/owner": "team-a"}),
+				withSecrets("pull-secret"))
+			tmpl.Spec.Publisher = "Example"
+			tmpl.Spec.Priority = 5
+
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"), withTemplate(tmpl))
+			Expect(cs.GetName()).To(Equal("fakeName"))
+			Expect(cs.GetLabels()).To(HaveKeyWithValue("team", "a"))
+			Expect(cs.GetAnnotations()).To(HaveKeyWithValue("example.com/owner", "team-a"))
+			Expect(cs.Spec.DisplayName).To(Equal("fakeDisplay"))
+			Expect(cs.Spec.Publisher).To(Equal("Example"))
+			Expect(cs.Spec.Priority).To(Equal(5))
+			Expect(cs.Spec.Secrets).To(Equal([]string{"pull-secret"}))
+		})
+	})
+	Describe("withInheritedConfig", func() {
+		It("should copy the priority, display metadata, and secrets of a CatalogSource", func() {
+			src := newCatalogSource("existing", "fakeNS", withSecrets("pull-secret"))
+			src.Spec.Priority = 10
+			src.Spec.Publisher = "Example"
+			src.Spec.Description = "Example operators"
+			src.Spec.UpdateStrategy = &v1alpha1.UpdateStrategy{
+				RegistryPoll: &v1alpha1.RegistryPoll{RawInterval: "10m"},
+			}
+			src.Spec.GrpcPodConfig = &v1alpha1.GrpcPodConfig{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}
+
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"), withInheritedConfig(src))
+			Expect(cs.Spec.Priority).To(Equal(10))
+			Expect(cs.Spec.DisplayName).To(Equal("fakeDisplay"))
+			Expect(cs.Spec.Publisher).To(Equal("Example"))
+			Expect(cs.Spec.Description).To(Equal("Example operators"))
+			Expect(cs.Spec.UpdateStrategy).To(BeNil())
+			Expect(cs.Spec.GrpcPodConfig).To(BeNil())
+			Expect(cs.Spec.Secrets).To(Equal([]string{"pull-secret"}))
+		})
+	})
+	Describe("ignoredCatalogSourceFields", func() {
+		It("should return the set fields that do not apply to an operator-sdk catalog", func() {
+			spec := v1alpha1.CatalogSourceSpec{Priority: 10}
+			Expect(ignoredCatalogSourceFields(spec)).To(BeEmpty())
+			spec.UpdateStrategy = &v1alpha1.UpdateStrategy{}
+			spec.GrpcPodConfig = &v1alpha1.GrpcPodConfig{}
+			Expect(ignoredCatalogSourceFields(spec)).To(Equal([]string{"spec.updateStrategy", "spec.grpcPodConfig"}))
+		})
+	})
 	Describe("withInstallPlanApproval", func() {
 		It("should set the display name and publisher of a CatalogSource", func() {
 			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"))
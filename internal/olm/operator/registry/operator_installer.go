@@ -23,6 +23,8 @@ import (
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -33,6 +35,10 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/olm/operator"
 )
 
+// resolutionFailedPolls is the number of consecutive install plan polls a subscription must
+// report a resolution failure for before waitForInstallPlan fails without waiting out its context.
+const resolutionFailedPolls = 25
+
 type OperatorInstaller struct {
 	CatalogSourceName     string
 	PackageName           string
@@ -42,6 +48,21 @@ type OperatorInstaller struct {
 	CatalogCreator        CatalogCreator
 	CatalogUpdater        CatalogUpdater
 	SupportedInstallModes sets.String
+	// Timer, if set, records the duration of each install stage.
+	Timer *operator.StageTimer
+	// PrintInstallPlan logs the resources in the generated InstallPlan before it is approved.
+	PrintInstallPlan bool
+	// Logger, if set, is used instead of the standard logger, ex. to tag each concurrent
+	// install's logs with a run ID.
+	Logger *log.Entry
+	// Patches are applied to the Subscription before it is created.
+	Patches []ResourcePatch
+	// CatalogSourceNamespace is the namespace of the CatalogSource the Subscription uses,
+	// if not the install namespace.
+	CatalogSourceNamespace string
+	// RequireServedChannel fails the install once the catalog is serving if its
+	// PackageManifest does not publish Channel, instead of waiting for OLM to resolve.
+	RequireServedChannel bool
 
 	cfg *operator.Configuration
 }
@@ -50,12 +71,34 @@ func NewOperatorInstaller(cfg *operator.Configuration) *OperatorInstaller {
 	return &OperatorInstaller{cfg: cfg}
 }
 
+// GetLogger returns Logger, or an entry of the standard logger if Logger is not set.
+func (o OperatorInstaller) GetLogger() *log.Entry {
+	return operator.LoggerOrStandard(o.Logger)
+}
+
+// SetCatalogSourceName sets CatalogSourceName to the name derived from PackageName,
+// logging it if the package name had characters that are not allowed in a name.
+func (o *OperatorInstaller) SetCatalogSourceName() {
+	o.CatalogSourceName = operator.CatalogNameForPackage(o.PackageName)
+	if o.CatalogSourceName != o.PackageName+"-catalog" {
+		o.GetLogger().Infof("Using catalog source name %q for package %q", o.CatalogSourceName, o.PackageName)
+	}
+}
+
 func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
-	cs, err := o.CatalogCreator.CreateCatalog(ctx, o.CatalogSourceName)
+	stageCtx, done := o.Timer.Track(ctx, "create catalog")
+	cs, err := o.CatalogCreator.CreateCatalog(stageCtx, o.CatalogSourceName)
+	done(err)
 	if err != nil {
 		return nil, fmt.Errorf("create catalog: %v", err)
 	}
-	log.Infof("Created CatalogSource: %s", cs.GetName())
+	o.GetLogger().Infof("Created CatalogSource: %s", cs.GetName())
+
+	if o.RequireServedChannel {
+		if err = o.checkServedChannel(ctx, cs); err != nil {
+			return nil, err
+		}
+	}
 
 	// TODO: OLM doesn't appear to propagate the "READY" connection status to the
 	// catalogsource in a timely manner even though its catalog-operator reports
@@ -79,22 +122,33 @@ func (o OperatorInstaller) InstallOperator(ctx context.Context) (*v1alpha1.Clust
 	}
 
 	// Wait for the Install Plan to be generated
-	if err = o.waitForInstallPlan(ctx, subscription); err != nil {
+	stageCtx, done = o.Timer.Track(ctx, "wait for install plan")
+	err = o.waitForInstallPlan(stageCtx, subscription)
+	done(err)
+	if err != nil {
 		return nil, err
 	}
 
+	if o.PrintInstallPlan {
+		if err = o.printInstallPlan(ctx, subscription); err != nil {
+			return nil, err
+		}
+	}
+
 	// Approve Install Plan for the subscription
 	if err = o.approveInstallPlan(ctx, subscription); err != nil {
 		return nil, err
 	}
 
 	// Wait for successfully installed CSV
-	csv, err := o.getInstalledCSV(ctx)
+	stageCtx, done = o.Timer.Track(ctx, "wait for CSV")
+	csv, err := o.getInstalledCSV(stageCtx)
+	done(err)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Infof("OLM has successfully installed %q", o.StartingCSV)
+	o.GetLogger().Infof("OLM has successfully installed %q", o.StartingCSV)
 
 	return csv, nil
 }
@@ -127,7 +181,7 @@ func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.Clust
 		return nil, fmt.Errorf("subscription for package %q not found", o.PackageName)
 	}
 
-	log.Infof("Found existing subscription with name %s and namespace %s", subscription.Name, subscription.Namespace)
+	o.GetLogger().Infof("Found existing subscription with name %s and namespace %s", subscription.Name, subscription.Namespace)
 
 	// Get existing catalog source from the subsription
 	catsrcKey := types.NamespacedName{
@@ -139,7 +193,7 @@ func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.Clust
 	if err := o.cfg.Client.Get(ctx, catsrcKey, cs); err != nil {
 		return nil, fmt.Errorf("error getting catalog source matching the existing subscription: %w", err)
 	}
-	log.Infof("Found existing catalog source with name %s and namespace %s", cs.Name, cs.Namespace)
+	o.GetLogger().Infof("Found existing catalog source with name %s and namespace %s", cs.Name, cs.Namespace)
 
 	// Update catalog source
 	err := o.CatalogUpdater.UpdateCatalog(ctx, cs)
@@ -152,6 +206,12 @@ func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.Clust
 		return nil, err
 	}
 
+	if o.PrintInstallPlan {
+		if err = o.printInstallPlan(ctx, subscription); err != nil {
+			return nil, err
+		}
+	}
+
 	// Approve Install Plan for the subscription
 	if err = o.approveInstallPlan(ctx, subscription); err != nil {
 		return nil, err
@@ -163,11 +223,39 @@ func (o OperatorInstaller) UpgradeOperator(ctx context.Context) (*v1alpha1.Clust
 		return nil, err
 	}
 
-	log.Infof("Successfully upgraded to %q", o.StartingCSV)
+	o.GetLogger().Infof("Successfully upgraded to %q", o.StartingCSV)
 
 	return csv, nil
 }
 
+// GetSucceededCSV returns the CSV StartingCSV if a Subscription for PackageName in the
+// operator's namespace has installed it and it has reached the "Succeeded" phase.
+// A nil CSV is returned if the operator is not installed at that version.
+func (o OperatorInstaller) GetSucceededCSV(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
+	subList := &v1alpha1.SubscriptionList{}
+	if err := o.cfg.Client.List(ctx, subList, client.InNamespace(o.cfg.Namespace)); err != nil {
+		return nil, fmt.Errorf("error getting list of subscriptions: %v", err)
+	}
+
+	for _, sub := range subList.Items {
+		if sub.Spec == nil || sub.Spec.Package != o.PackageName || sub.Status.InstalledCSV != o.StartingCSV {
+			continue
+		}
+		csv := &v1alpha1.ClusterServiceVersion{}
+		key := types.NamespacedName{Namespace: o.cfg.Namespace, Name: o.StartingCSV}
+		if err := o.cfg.Client.Get(ctx, key, csv); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("error getting installed CSV: %w", err)
+		}
+		if csv.Status.Phase == v1alpha1.CSVPhaseSucceeded {
+			return csv, nil
+		}
+	}
+	return nil, nil
+}
+
 //nolint:unused
 func (o OperatorInstaller) waitForCatalogSource(ctx context.Context, cs *v1alpha1.CatalogSource) error {
 	catSrcKey := client.ObjectKeyFromObject(cs)
@@ -199,22 +287,7 @@ func (o OperatorInstaller) ensureOperatorGroup(ctx context.Context) error {
 		return err
 	}
 
-	supported := o.SupportedInstallModes
-
-	// --install-mode was given
-	if !o.InstallMode.IsEmpty() {
-		if o.InstallMode.InstallModeType == v1alpha1.InstallModeTypeSingleNamespace &&
-			o.InstallMode.TargetNamespaces[0] == o.cfg.Namespace {
-			return fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, o.cfg.Namespace)
-		}
-
-		supported = supported.Intersection(sets.NewString(string(o.InstallMode.InstallModeType)))
-		if supported.Len() == 0 {
-			return fmt.Errorf("operator %q does not support install mode %q", o.StartingCSV, o.InstallMode.InstallModeType)
-		}
-	}
-
-	targetNamespaces, err := o.getTargetNamespaces(supported)
+	targetNamespaces, err := o.resolveTargetNamespaces()
 	if err != nil {
 		return err
 	}
@@ -223,7 +296,7 @@ func (o OperatorInstaller) ensureOperatorGroup(ctx context.Context) error {
 		if og, err = o.createOperatorGroup(ctx, targetNamespaces); err != nil {
 			return fmt.Errorf("create operator group: %v", err)
 		}
-		log.Infof("OperatorGroup %q created", og.Name)
+		o.GetLogger().Infof("OperatorGroup %q created", og.Name)
 	} else if err := o.isOperatorGroupCompatible(*og, targetNamespaces); err != nil {
 		return err
 	}
@@ -231,9 +304,45 @@ func (o OperatorInstaller) ensureOperatorGroup(ctx context.Context) error {
 	return nil
 }
 
+// CheckOperatorGroup returns an error if the namespace's existing OperatorGroup is not compatible
+// with the selected install mode, so conflicts can be caught before a catalog is created.
+// Unlike ensureOperatorGroup, it never creates an OperatorGroup.
+func (o OperatorInstaller) CheckOperatorGroup(ctx context.Context) error {
+	og, ogFound, err := o.getOperatorGroup(ctx)
+	if err != nil || !ogFound {
+		return err
+	}
+	targetNamespaces, err := o.resolveTargetNamespaces()
+	if err != nil {
+		return err
+	}
+	return o.isOperatorGroupCompatible(*og, targetNamespaces)
+}
+
+// resolveTargetNamespaces returns the OperatorGroup target namespaces required by
+// the selected install mode, or by the CSV's supported install modes if none was selected.
+func (o OperatorInstaller) resolveTargetNamespaces() ([]string, error) {
+	supported := o.SupportedInstallModes
+
+	// --install-mode was given
+	if !o.InstallMode.IsEmpty() {
+		if o.InstallMode.InstallModeType == v1alpha1.InstallModeTypeSingleNamespace &&
+			o.InstallMode.TargetNamespaces[0] == o.cfg.Namespace {
+			return nil, fmt.Errorf("use install mode %q to watch operator's namespace %q", v1alpha1.InstallModeTypeOwnNamespace, o.cfg.Namespace)
+		}
+
+		supported = supported.Intersection(sets.NewString(string(o.InstallMode.InstallModeType)))
+		if supported.Len() == 0 {
+			return nil, fmt.Errorf("operator %q does not support install mode %q", o.StartingCSV, o.InstallMode.InstallModeType)
+		}
+	}
+
+	return o.getTargetNamespaces(supported)
+}
+
 func (o *OperatorInstaller) createOperatorGroup(ctx context.Context, targetNamespaces []string) (*v1.OperatorGroup, error) {
 	og := newSDKOperatorGroup(o.cfg.Namespace, withTargetNamespaces(targetNamespaces...))
-	if err := o.cfg.Client.Create(ctx, og); err != nil {
+	if err := createWithRetry(ctx, o.cfg.Client, og); err != nil {
 		return nil, err
 	}
 	return og, nil
@@ -249,12 +358,21 @@ func (o *OperatorInstaller) isOperatorGroupCompatible(og v1.OperatorGroup, targe
 	targets := sets.NewString(targetNamespaces...)
 	ogtargets := sets.NewString(og.Spec.TargetNamespaces...)
 	if !ogtargets.Equal(targets) {
-		return fmt.Errorf("existing operatorgroup %q is not compatible with install mode %q", og.Name, o.InstallMode)
+		return fmt.Errorf("existing operatorgroup %q is not compatible with install mode %q: it targets %s, but the install mode requires %s",
+			og.Name, o.InstallMode, describeTargetNamespaces(og.Spec.TargetNamespaces), describeTargetNamespaces(targetNamespaces))
 	}
 
 	return nil
 }
 
+// describeTargetNamespaces describes an OperatorGroup's target namespaces for error messages.
+func describeTargetNamespaces(targetNamespaces []string) string {
+	if len(targetNamespaces) == 0 {
+		return "all namespaces"
+	}
+	return fmt.Sprintf("namespaces %+q", targetNamespaces)
+}
+
 // getOperatorGroup returns true if an OperatorGroup in the desired namespace was found.
 // If more than one operator group exists in namespace, this function will return an error
 // since CSVs in namespace will have an error status in that case.
@@ -277,19 +395,39 @@ func (o OperatorInstaller) getOperatorGroup(ctx context.Context) (*v1.OperatorGr
 }
 
 func (o OperatorInstaller) createSubscription(ctx context.Context, csName string) (*v1alpha1.Subscription, error) {
-	sub := newSubscription(o.StartingCSV, o.cfg.Namespace,
-		withPackageChannel(o.PackageName, o.Channel, o.StartingCSV),
-		withCatalogSource(csName, o.cfg.Namespace),
-		withInstallPlanApproval(v1alpha1.ApprovalManual))
+	sub, err := o.buildSubscription(csName)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := o.cfg.Client.Create(ctx, sub); err != nil {
+	if err := createWithRetry(ctx, o.cfg.Client, sub); err != nil {
 		return nil, fmt.Errorf("error creating subscription: %w", err)
 	}
-	log.Infof("Created Subscription: %s", sub.Name)
+	o.GetLogger().Infof("Created Subscription: %s", sub.Name)
 
 	return sub, nil
 }
 
+// buildSubscription returns the Subscription to CatalogSource csName that createSubscription creates.
+func (o OperatorInstaller) buildSubscription(csName string) (*v1alpha1.Subscription, error) {
+	sub := newSubscription(o.StartingCSV, o.cfg.Namespace,
+		withPackageChannel(o.PackageName, o.Channel, o.StartingCSV),
+		withCatalogSource(csName, o.getCatalogSourceNamespace()),
+		withInstallPlanApproval(v1alpha1.ApprovalManual))
+	if err := patchSubscription(sub, o.Patches); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// getCatalogSourceNamespace returns CatalogSourceNamespace, or the install namespace if it is not set.
+func (o OperatorInstaller) getCatalogSourceNamespace() string {
+	if o.CatalogSourceNamespace == "" {
+		return o.cfg.Namespace
+	}
+	return o.CatalogSourceNamespace
+}
+
 func (o OperatorInstaller) getInstalledCSV(ctx context.Context) (*v1alpha1.ClusterServiceVersion, error) {
 	c := olmclient.Client{KubeClient: o.cfg.Client}
 
@@ -299,7 +437,7 @@ func (o OperatorInstaller) getInstalledCSV(ctx context.Context) (*v1alpha1.Clust
 		Name:      o.StartingCSV,
 		Namespace: o.cfg.Namespace,
 	}
-	log.Infof("Waiting for ClusterServiceVersion %q to reach 'Succeeded' phase", nn)
+	o.GetLogger().Infof("Waiting for ClusterServiceVersion %q to reach 'Succeeded' phase", nn)
 	if err := c.DoCSVWait(ctx, nn); err != nil {
 		return nil, fmt.Errorf("error waiting for CSV to install: %w", err)
 	}
@@ -333,11 +471,56 @@ func (o OperatorInstaller) approveInstallPlan(ctx context.Context, sub *v1alpha1
 		return err
 	}
 
-	log.Infof("Approved InstallPlan %s for the Subscription: %s", ipKey.Name, sub.Name)
+	o.GetLogger().Infof("Approved InstallPlan %s for the Subscription: %s", ipKey.Name, sub.Name)
+
+	return nil
+}
 
+// printInstallPlan logs the resources the subscription's install plan will create.
+func (o OperatorInstaller) printInstallPlan(ctx context.Context, sub *v1alpha1.Subscription) error {
+	steps, err := o.getInstallPlanSteps(ctx, sub)
+	if err != nil {
+		return err
+	}
+	o.GetLogger().Infof("InstallPlan %s will create the following resources:", sub.Status.InstallPlanRef.Name)
+	for _, step := range steps {
+		r := step.Resource
+		gv := r.Version
+		if r.Group != "" {
+			gv = r.Group + "/" + r.Version
+		}
+		o.GetLogger().Infof("  %s %q (%s): %s", r.Kind, r.Name, gv, step.Status)
+	}
 	return nil
 }
 
+// getInstallPlanSteps waits for OLM to resolve the subscription's install plan and returns its steps.
+func (o OperatorInstaller) getInstallPlanSteps(ctx context.Context, sub *v1alpha1.Subscription) ([]*v1alpha1.Step, error) {
+	ip := v1alpha1.InstallPlan{}
+	ipKey := types.NamespacedName{
+		Name:      sub.Status.InstallPlanRef.Name,
+		Namespace: sub.Status.InstallPlanRef.Namespace,
+	}
+
+	ipCheck := wait.ConditionFunc(func() (done bool, err error) {
+		if err := o.cfg.Client.Get(ctx, ipKey, &ip); err != nil {
+			return false, err
+		}
+		switch ip.Status.Phase {
+		case v1alpha1.InstallPlanPhaseFailed:
+			return false, fmt.Errorf("install plan %s failed", ipKey.Name)
+		case v1alpha1.InstallPlanPhaseNone, v1alpha1.InstallPlanPhasePlanning:
+			return false, nil
+		}
+		return true, nil
+	})
+
+	if err := wait.PollImmediateUntil(200*time.Millisecond, ipCheck, ctx.Done()); err != nil {
+		return nil, fmt.Errorf("install plan %s was not resolved: %v", ipKey.Name, err)
+	}
+	return ip.Status.Plan, nil
+}
+
 // waitForInstallPlan verifies if an Install Plan exists through subscription status
 func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1.Subscription) error {
 	subKey := types.NamespacedName{
@@ -345,6 +528,10 @@ func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1
 		Name:      sub.GetName(),
 	}
 
+	// OLM commonly reports a resolution failure right after the subscription is created,
+	// while its cache of a new catalog is still empty, so only fail fast once the failure
+	// has lasted resolutionFailedPolls polls or outlasted the catalog becoming READY.
+	failedPolls, catalogReady := 0, false
 	ipCheck := wait.ConditionFunc(func() (done bool, err error) {
 		if err := o.cfg.Client.Get(ctx, subKey, sub); err != nil {
 			return false, err
@@ -352,6 +539,16 @@ func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1
 		if sub.Status.InstallPlanRef != nil {
 			return true, nil
 		}
+		resErr := getResolutionError(sub)
+		if resErr == nil {
+			failedPolls = 0
+			return false, nil
+		}
+		failedPolls++
+		if catalogReady || failedPolls >= resolutionFailedPolls {
+			return false, resErr
+		}
+		catalogReady = o.isSubscriptionCatalogReady(ctx, sub)
 		return false, nil
 	})
 
@@ -361,6 +558,57 @@ func (o OperatorInstaller) waitForInstallPlan(ctx context.Context, sub *v1alpha1
 	return nil
 }
 
+// checkServedChannel waits for cs to serve PackageName, then returns an error
+// if the served PackageManifest does not publish Channel.
+func (o OperatorInstaller) checkServedChannel(ctx context.Context, cs *v1alpha1.CatalogSource) error {
+	var pkg *PackageManifestStatus
+	var lastErr error
+	pkgCheck := wait.ConditionFunc(func() (bool, error) {
+		pkg, lastErr = GetPackageManifest(ctx, o.cfg.Client, o.PackageName, cs.GetName(), cs.GetNamespace())
+		return lastErr == nil, nil
+	})
+
+	if err := wait.PollImmediateUntil(200*time.Millisecond, pkgCheck, ctx.Done()); err != nil {
+		if lastErr != nil {
+			err = lastErr
+		}
+		return fmt.Errorf("catalog source %q did not serve package %q: %v", cs.GetName(), o.PackageName, err)
+	}
+	if _, err := pkg.GetChannel(o.Channel); err != nil {
+		return fmt.Errorf("package %q: %v", o.PackageName, err)
+	}
+	return nil
+}
+
+// isSubscriptionCatalogReady returns true if the CatalogSource sub uses reports a READY connection.
+func (o OperatorInstaller) isSubscriptionCatalogReady(ctx context.Context, sub *v1alpha1.Subscription) bool {
+	if sub.Spec == nil {
+		return false
+	}
+	cs := &v1alpha1.CatalogSource{}
+	csKey := types.NamespacedName{Namespace: sub.Spec.CatalogSourceNamespace, Name: sub.Spec.CatalogSource}
+	if err := o.cfg.Client.Get(ctx, csKey, cs); err != nil {
+		return false
+	}
+	return cs.Status.GRPCConnectionState != nil && cs.Status.GRPCConnectionState.LastObservedState == "READY"
+}
+
+// getResolutionError returns an error describing what the subscription requested
+// if OLM has reported that it failed to resolve, ex. because the catalog has
+// no bundle satisfying the requested package, channel, and starting CSV.
+func getResolutionError(sub *v1alpha1.Subscription) error {
+	cond := sub.Status.GetCondition(v1alpha1.SubscriptionResolutionFailed)
+	if cond.Status != corev1.ConditionTrue {
+		return nil
+	}
+	var pkg, channel, startingCSV string
+	if sub.Spec != nil {
+		pkg, channel, startingCSV = sub.Spec.Package, sub.Spec.Channel, sub.Spec.StartingCSV
+	}
+	return fmt.Errorf("subscription %s for package %q, channel %q, and starting CSV %q failed to resolve (%s): %s",
+		sub.GetName(), pkg, channel, startingCSV, cond.Reason, cond.Message)
+}
+
 func (o *OperatorInstaller) getTargetNamespaces(supported sets.String) ([]string, error) {
 	switch {
 	case supported.Has(string(v1alpha1.InstallModeTypeAllNamespaces)):
@@ -370,7 +618,7 @@ func (o *OperatorInstaller) getTargetNamespaces(supported sets.String) ([]string
 	case supported.Has(string(v1alpha1.InstallModeTypeSingleNamespace)):
 		return o.InstallMode.TargetNamespaces, nil
 	case supported.Has(string(v1alpha1.InstallModeTypeMultiNamespace)):
-		log.Warn("The selected install mode MultiNamespace may cause tenancy issues and is not recommended")
+		o.GetLogger().Warn("The selected install mode MultiNamespace may cause tenancy issues and is not recommended")
 		return o.InstallMode.TargetNamespaces, nil
 	default:
 		return nil, fmt.Errorf("no supported install modes")
@@ -16,13 +16,16 @@ package registry
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -41,6 +44,18 @@ var _ = Describe("OperatorInstaller", func() {
 		})
 	})
 
+	Describe("GetLogger", func() {
+		It("should return an entry of the standard logger if Logger is not set", func() {
+			oi := NewOperatorInstaller(&operator.Configuration{})
+			Expect(oi.GetLogger().Logger).To(Equal(log.StandardLogger()))
+		})
+		It("should return Logger if set", func() {
+			oi := NewOperatorInstaller(&operator.Configuration{})
+			oi.Logger = log.WithField("run", "abc12")
+			Expect(oi.GetLogger().Data).To(HaveKeyWithValue("run", "abc12"))
+		})
+	})
+
 	Describe("createSubscription", func() {
 		var (
 			oi  *OperatorInstaller
@@ -74,6 +89,15 @@ var _ = Describe("OperatorInstaller", func() {
 			Expect(retSub.GetNamespace()).To(Equal(sub.GetNamespace()))
 		})
 
+		It("should reference a catalog source in another namespace", func() {
+			oi.CatalogSourceNamespace = "olm"
+			sub, err := oi.createSubscription(context.TODO(), "operatorhubio-catalog")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sub.GetNamespace()).To(Equal("testns"))
+			Expect(sub.Spec.CatalogSource).To(Equal("operatorhubio-catalog"))
+			Expect(sub.Spec.CatalogSourceNamespace).To(Equal("olm"))
+		})
+
 		It("should pass through any client errors (duplicate)", func() {
 
 			sub := newSubscription(oi.StartingCSV, oi.cfg.Namespace, withCatalogSource("duplicate", oi.cfg.Namespace))
@@ -145,6 +169,70 @@ var _ = Describe("OperatorInstaller", func() {
 		})
 	})
 
+	Describe("GetSucceededCSV", func() {
+		var (
+			cfg *operator.Configuration
+			oi  *OperatorInstaller
+			sch *runtime.Scheme
+			sub *v1alpha1.Subscription
+			csv *v1alpha1.ClusterServiceVersion
+		)
+		BeforeEach(func() {
+			cfg = &operator.Configuration{}
+			sch = runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+
+			oi = NewOperatorInstaller(cfg)
+			oi.PackageName = "somepackage"
+			oi.StartingCSV = "somename"
+			oi.cfg.Namespace = "somenamespace"
+
+			sub = newSubscription(oi.StartingCSV, oi.cfg.Namespace,
+				withPackageChannel(oi.PackageName, "alpha", oi.StartingCSV))
+			sub.Status.InstalledCSV = oi.StartingCSV
+			csv = &v1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "somename",
+					Namespace: "somenamespace",
+				},
+				Status: v1alpha1.ClusterServiceVersionStatus{
+					Phase: v1alpha1.CSVPhaseSucceeded,
+				},
+			}
+		})
+		It("should return the CSV if it is installed and has succeeded", func() {
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(sub, csv).Build()
+
+			found, err := oi.GetSucceededCSV(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).ToNot(BeNil())
+			Expect(found.GetName()).To(Equal("somename"))
+		})
+		It("should return nil if no subscription exists for the package", func() {
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(csv).Build()
+
+			found, err := oi.GetSucceededCSV(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+		It("should return nil if a different CSV is installed", func() {
+			sub.Status.InstalledCSV = "othername"
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(sub, csv).Build()
+
+			found, err := oi.GetSucceededCSV(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+		It("should return nil if the CSV has not succeeded", func() {
+			csv.Status.Phase = v1alpha1.CSVPhaseInstalling
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(sub, csv).Build()
+
+			found, err := oi.GetSucceededCSV(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeNil())
+		})
+	})
+
 	Describe("approveInstallPlan", func() {
 		var (
 			oi  *OperatorInstaller
@@ -211,6 +299,69 @@ var _ = Describe("OperatorInstaller", func() {
 		})
 	})
 
+	Describe("getInstallPlanSteps", func() {
+		var (
+			oi  *OperatorInstaller
+			sch *runtime.Scheme
+			sub *v1alpha1.Subscription
+		)
+		BeforeEach(func() {
+			cfg := &operator.Configuration{}
+			sch = runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+			oi = NewOperatorInstaller(cfg)
+			sub = &v1alpha1.Subscription{
+				Status: v1alpha1.SubscriptionStatus{
+					InstallPlanRef: &corev1.ObjectReference{
+						Name:      "fakeName",
+						Namespace: "fakeNS",
+					},
+				},
+			}
+		})
+
+		It("should return the steps of a resolved install plan", func() {
+			oi.cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				&v1alpha1.InstallPlan{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "fakeName",
+						Namespace: "fakeNS",
+					},
+					Status: v1alpha1.InstallPlanStatus{
+						Phase: v1alpha1.InstallPlanPhaseRequiresApproval,
+						Plan: []*v1alpha1.Step{
+							{Resource: v1alpha1.StepResource{
+								Kind: "ClusterServiceVersion", Name: "memcached-operator.v0.0.1",
+								Group: "operators.coreos.com", Version: "v1alpha1",
+							}, Status: v1alpha1.StepStatusUnknown},
+						},
+					},
+				},
+			).Build()
+
+			steps, err := oi.getInstallPlanSteps(context.TODO(), sub)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(steps).To(HaveLen(1))
+			Expect(steps[0].Resource.Name).To(Equal("memcached-operator.v0.0.1"))
+		})
+		It("should return an error if the install plan failed", func() {
+			oi.cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				&v1alpha1.InstallPlan{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "fakeName",
+						Namespace: "fakeNS",
+					},
+					Status: v1alpha1.InstallPlanStatus{
+						Phase: v1alpha1.InstallPlanPhaseFailed,
+					},
+				},
+			).Build()
+
+			_, err := oi.getInstallPlanSteps(context.TODO(), sub)
+			Expect(err).To(MatchError(ContainSubstring("install plan fakeName failed")))
+		})
+	})
+
 	Describe("waitForInstallPlan", func() {
 		var (
 			oi  *OperatorInstaller
@@ -254,6 +405,108 @@ var _ = Describe("OperatorInstaller", func() {
 			err = oi.waitForInstallPlan(context.TODO(), sub)
 			Expect(err).ToNot(HaveOccurred())
 		})
+		Context("with a subscription that failed to resolve", func() {
+			var sub *v1alpha1.Subscription
+			BeforeEach(func() {
+				sub = newSubscription(oi.StartingCSV, oi.cfg.Namespace,
+					withPackageChannel("fakePackage", "fakeChannel", oi.StartingCSV),
+					withCatalogSource("fakeCatalog", oi.cfg.Namespace))
+				sub.Status.SetCondition(v1alpha1.SubscriptionCondition{
+					Type:    v1alpha1.SubscriptionResolutionFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "ConstraintsNotSatisfiable",
+					Message: "no operators found in channel fakeChannel of package fakePackage",
+				})
+				Expect(oi.cfg.Client.Create(context.TODO(), sub)).To(Succeed())
+			})
+			expectResolutionError := func(err error) {
+				Expect(err).To(MatchError(ContainSubstring(`subscription fakename-sub for package "fakePackage", ` +
+					`channel "fakeChannel", and starting CSV "fakeName" failed to resolve (ConstraintsNotSatisfiable): ` +
+					`no operators found in channel fakeChannel of package fakePackage`)))
+			}
+
+			It("should return a resolution error once it lasts across polls without waiting for the context to be done", func() {
+				ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+				defer cancel()
+				expectResolutionError(oi.waitForInstallPlan(ctx, sub))
+				Expect(ctx.Err()).ToNot(HaveOccurred())
+			})
+			It("should return a resolution error once it outlasts the catalog becoming ready", func() {
+				cs := &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "fakeCatalog", Namespace: oi.cfg.Namespace}}
+				cs.Status.GRPCConnectionState = &v1alpha1.GRPCConnectionState{LastObservedState: "READY"}
+				Expect(oi.cfg.Client.Create(context.TODO(), cs)).To(Succeed())
+
+				start := time.Now()
+				expectResolutionError(oi.waitForInstallPlan(context.TODO(), sub))
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			})
+			It("should succeed if the failure clears before it lasts across polls", func() {
+				go func() {
+					defer GinkgoRecover()
+					time.Sleep(time.Second)
+					resolved := &v1alpha1.Subscription{}
+					Expect(oi.cfg.Client.Get(context.TODO(), crclient.ObjectKeyFromObject(sub), resolved)).To(Succeed())
+					resolved.Status.RemoveConditions(v1alpha1.SubscriptionResolutionFailed)
+					resolved.Status.InstallPlanRef = &corev1.ObjectReference{Name: "fakeName", Namespace: "fakeNS"}
+					Expect(oi.cfg.Client.Update(context.TODO(), resolved)).To(Succeed())
+				}()
+
+				ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+				defer cancel()
+				Expect(oi.waitForInstallPlan(ctx, sub)).To(Succeed())
+			})
+		})
+	})
+
+	Describe("checkServedChannel", func() {
+		var (
+			oi *OperatorInstaller
+			cs *v1alpha1.CatalogSource
+		)
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			// The fake client only stores kinds known to its scheme.
+			sch.AddKnownTypeWithName(PackageManifestListGVK.GroupVersion().WithKind("PackageManifest"), &unstructured.Unstructured{})
+			sch.AddKnownTypeWithName(PackageManifestListGVK, &unstructured.UnstructuredList{})
+			pm := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"defaultChannel": "stable",
+					"channels": []interface{}{
+						map[string]interface{}{"name": "alpha", "currentCSV": "fakePackage.v0.2.0"},
+						map[string]interface{}{"name": "stable", "currentCSV": "fakePackage.v0.1.0"},
+					},
+				},
+			}}
+			pm.SetGroupVersionKind(PackageManifestListGVK.GroupVersion().WithKind("PackageManifest"))
+			pm.SetName("fakePackage")
+			pm.SetNamespace("fakeNS")
+			pm.SetLabels(map[string]string{
+				PackageManifestCatalogLabel:          "fakePackage-catalog",
+				PackageManifestCatalogNamespaceLabel: "fakeNS",
+			})
+			cfg := &operator.Configuration{Namespace: "fakeNS"}
+			cfg.Client = fake.NewClientBuilder().WithScheme(sch).WithObjects(pm).Build()
+
+			oi = NewOperatorInstaller(cfg)
+			oi.PackageName = "fakePackage"
+			cs = &v1alpha1.CatalogSource{ObjectMeta: metav1.ObjectMeta{Name: "fakePackage-catalog", Namespace: "fakeNS"}}
+		})
+		It("should succeed if the served package publishes the channel", func() {
+			oi.Channel = "alpha"
+			Expect(oi.checkServedChannel(context.TODO(), cs)).To(Succeed())
+		})
+		It("should return an error if the served package does not publish the channel", func() {
+			oi.Channel = "beta"
+			Expect(oi.checkServedChannel(context.TODO(), cs)).To(MatchError(
+				`package "fakePackage": channel "beta" not found, available channels: ["alpha" "stable"]`))
+		})
+		It("should return the last lookup error if the catalog never serves the package", func() {
+			cs.Name = "other-catalog"
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+			Expect(oi.checkServedChannel(ctx, cs)).To(MatchError(ContainSubstring(
+				`catalog source "other-catalog" did not serve package "fakePackage": package "fakePackage" not found`)))
+		})
 	})
 
 	Describe("ensureOperatorGroup", func() {
@@ -488,6 +741,46 @@ var _ = Describe("OperatorInstaller", func() {
 		})
 	})
 
+	Describe("CheckOperatorGroup", func() {
+		var (
+			oi     OperatorInstaller
+			client crclient.Client
+		)
+		BeforeEach(func() {
+			sch := runtime.NewScheme()
+			Expect(v1.AddToScheme(sch)).To(Succeed())
+			client = fake.NewClientBuilder().WithScheme(sch).Build()
+			oi = OperatorInstaller{
+				cfg: &operator.Configuration{
+					Scheme:    sch,
+					Client:    client,
+					Namespace: "testns",
+				},
+			}
+			oi.SupportedInstallModes = operator.GetSupportedInstallModes([]v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeOwnNamespace, Supported: true},
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			})
+			_ = oi.InstallMode.Set(string(v1alpha1.InstallModeTypeOwnNamespace))
+		})
+		It("should not create an OperatorGroup if none exists", func() {
+			Expect(oi.CheckOperatorGroup(context.TODO())).To(Succeed())
+			_, found, err := oi.getOperatorGroup(context.TODO())
+			Expect(err).To(BeNil())
+			Expect(found).To(BeFalse())
+		})
+		It("should succeed if the existing OperatorGroup is compatible", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns", "testns")
+			Expect(oi.CheckOperatorGroup(context.TODO())).To(Succeed())
+		})
+		It("should describe the conflict if the existing OperatorGroup is not compatible", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns")
+			err := oi.CheckOperatorGroup(context.TODO())
+			Expect(err).To(MatchError(`existing operatorgroup "existing-og" is not compatible with install mode "OwnNamespace": ` +
+				`it targets all namespaces, but the install mode requires namespaces ["testns"]`))
+		})
+	})
+
 	Describe("isOperatorGroupCompatible", func() {
 		var (
 			oi OperatorInstaller
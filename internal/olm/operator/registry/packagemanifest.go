@@ -0,0 +1,100 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PackageManifestListGVK is the kind of the list of packages OLM's package server
+// serves for every CatalogSource. The package server's types are not vendored,
+// so only the fields needed to subscribe are decoded.
+var PackageManifestListGVK = schema.GroupVersionKind{
+	Group:   "packages.operators.coreos.com",
+	Version: "v1",
+	Kind:    "PackageManifestList",
+}
+
+// Labels the package server sets on a PackageManifest to identify its CatalogSource.
+const (
+	PackageManifestCatalogLabel          = "catalog"
+	PackageManifestCatalogNamespaceLabel = "catalog-namespace"
+)
+
+// PackageManifestStatus is the status of a package served by a CatalogSource.
+type PackageManifestStatus struct {
+	DefaultChannel string           `json:"defaultChannel"`
+	Channels       []PackageChannel `json:"channels"`
+}
+
+// PackageChannel is a channel of a served package.
+type PackageChannel struct {
+	Name           string `json:"name"`
+	CurrentCSV     string `json:"currentCSV"`
+	CurrentCSVDesc struct {
+		InstallModes []v1alpha1.InstallMode `json:"installModes"`
+	} `json:"currentCSVDesc"`
+}
+
+// GetPackageManifest returns the status of package pkgName served by CatalogSource catalogNamespace/catalogName.
+func GetPackageManifest(ctx context.Context, c client.Reader, pkgName, catalogName, catalogNamespace string) (*PackageManifestStatus, error) {
+	list := unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(PackageManifestListGVK)
+	opts := []client.ListOption{
+		client.InNamespace(catalogNamespace),
+		client.MatchingLabels{PackageManifestCatalogLabel: catalogName, PackageManifestCatalogNamespaceLabel: catalogNamespace},
+	}
+	if err := c.List(ctx, &list, opts...); err != nil {
+		return nil, fmt.Errorf("list package manifests: %v", err)
+	}
+
+	for _, item := range list.Items {
+		if item.GetName() != pkgName {
+			continue
+		}
+		status := PackageManifestStatus{}
+		content, _, err := unstructured.NestedMap(item.Object, "status")
+		if err != nil {
+			return nil, fmt.Errorf("read package manifest %q status: %v", pkgName, err)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &status); err != nil {
+			return nil, fmt.Errorf("decode package manifest %q status: %v", pkgName, err)
+		}
+		return &status, nil
+	}
+	return nil, fmt.Errorf("package %q not found in catalog source %q in namespace %q", pkgName, catalogName, catalogNamespace)
+}
+
+// GetChannel returns the channel named name, or the default channel if name is empty.
+func (s PackageManifestStatus) GetChannel(name string) (*PackageChannel, error) {
+	if name == "" {
+		name = s.DefaultChannel
+	}
+	available := make([]string, len(s.Channels))
+	for i, ch := range s.Channels {
+		if ch.Name == name {
+			return &s.Channels[i], nil
+		}
+		available[i] = ch.Name
+	}
+	return nil, fmt.Errorf("channel %q not found, available channels: %+q", name, available)
+}
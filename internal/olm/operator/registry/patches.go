@@ -0,0 +1,133 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// ResourcePatch is an RFC 6902 JSON patch applied to a generated resource of Kind
+// before it is created. Kind must be CatalogSource or Subscription.
+type ResourcePatch struct {
+	Kind  string          `json:"kind"`
+	Patch json.RawMessage `json:"patch"`
+}
+
+// ValidatePatches returns an error if a patch targets an unsupported kind or is not a valid JSON patch.
+func ValidatePatches(patches []ResourcePatch) error {
+	for i, p := range patches {
+		switch p.Kind {
+		case v1alpha1.CatalogSourceKind, v1alpha1.SubscriptionKind:
+		default:
+			return fmt.Errorf("patch %d: kind %q cannot be patched, must be one of: %+q",
+				i, p.Kind, []string{v1alpha1.CatalogSourceKind, v1alpha1.SubscriptionKind})
+		}
+		if _, err := jsonpatch.DecodePatch(p.Patch); err != nil {
+			return fmt.Errorf("patch %d: invalid JSON patch: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// patchCatalogSource applies CatalogSource patches to cs, and returns an error if they fail to apply
+// or change fields that the catalog's registry pod depends on.
+func patchCatalogSource(cs *v1alpha1.CatalogSource, patches []ResourcePatch) error {
+	patched := &v1alpha1.CatalogSource{}
+	if err := applyPatches(cs, patched, v1alpha1.CatalogSourceKind, patches); err != nil {
+		return err
+	}
+	if err := checkUnchanged(v1alpha1.CatalogSourceKind, cs.GetName(), []protectedField{
+		{"metadata.name", cs.GetName(), patched.GetName()},
+		{"metadata.namespace", cs.GetNamespace(), patched.GetNamespace()},
+		{"spec.sourceType", cs.Spec.SourceType, patched.Spec.SourceType},
+		{"spec.address", cs.Spec.Address, patched.Spec.Address},
+		{"spec.image", cs.Spec.Image, patched.Spec.Image},
+		{"spec.configMap", cs.Spec.ConfigMap, patched.Spec.ConfigMap},
+	}); err != nil {
+		return err
+	}
+	*cs = *patched
+	return nil
+}
+
+// patchSubscription applies Subscription patches to sub, and returns an error if they fail to apply
+// or change fields that the install depends on.
+func patchSubscription(sub *v1alpha1.Subscription, patches []ResourcePatch) error {
+	patched := &v1alpha1.Subscription{}
+	if err := applyPatches(sub, patched, v1alpha1.SubscriptionKind, patches); err != nil {
+		return err
+	}
+	if patched.Spec == nil {
+		return fmt.Errorf("patches for %s %q removed spec", v1alpha1.SubscriptionKind, sub.GetName())
+	}
+	if err := checkUnchanged(v1alpha1.SubscriptionKind, sub.GetName(), []protectedField{
+		{"metadata.name", sub.GetName(), patched.GetName()},
+		{"metadata.namespace", sub.GetNamespace(), patched.GetNamespace()},
+		{"spec.name", sub.Spec.Package, patched.Spec.Package},
+		{"spec.source", sub.Spec.CatalogSource, patched.Spec.CatalogSource},
+		{"spec.sourceNamespace", sub.Spec.CatalogSourceNamespace, patched.Spec.CatalogSourceNamespace},
+		{"spec.startingCSV", sub.Spec.StartingCSV, patched.Spec.StartingCSV},
+		{"spec.installPlanApproval", sub.Spec.InstallPlanApproval, patched.Spec.InstallPlanApproval},
+	}); err != nil {
+		return err
+	}
+	*sub = *patched
+	return nil
+}
+
+// applyPatches applies each patch for kind to in, and decodes the result into out.
+func applyPatches(in, out interface{}, kind string, patches []ResourcePatch) error {
+	doc, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %v", kind, err)
+	}
+	for i, p := range patches {
+		if p.Kind != kind {
+			continue
+		}
+		patch, err := jsonpatch.DecodePatch(p.Patch)
+		if err != nil {
+			return fmt.Errorf("patch %d: invalid JSON patch: %v", i, err)
+		}
+		if doc, err = patch.Apply(doc); err != nil {
+			return fmt.Errorf("patch %d: apply to %s: %v", i, kind, err)
+		}
+	}
+	if err := json.Unmarshal(doc, out); err != nil {
+		return fmt.Errorf("patched %s is invalid: %v", kind, err)
+	}
+	return nil
+}
+
+// protectedField is a field patches must not change, with its values before and after patching.
+type protectedField struct {
+	path          string
+	before, after interface{}
+}
+
+// checkUnchanged returns an error naming the first field whose value was changed by patches.
+func checkUnchanged(kind, name string, fields []protectedField) error {
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.before, f.after) {
+			return fmt.Errorf("patches for %s %q must not change %s", kind, name, f.path)
+		}
+	}
+	return nil
+}
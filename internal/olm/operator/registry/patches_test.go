@@ -0,0 +1,102 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+var _ = Describe("Patches", func() {
+	newPatch := func(kind, patch string) ResourcePatch {
+		return ResourcePatch{Kind: kind, Patch: json.RawMessage(patch)}
+	}
+
+	Describe("ValidatePatches", func() {
+		It("should succeed for valid patches", func() {
+			Expect(ValidatePatches([]ResourcePatch{
+				newPatch("CatalogSource", `[{"op": "add", "path": "/spec/priority", "value": 10}]`),
+				newPatch("Subscription", `[{"op": "add", "path": "/spec/config", "value": {}}]`),
+			})).To(Succeed())
+		})
+		It("should return an error for an unsupported kind", func() {
+			err := ValidatePatches([]ResourcePatch{newPatch("OperatorGroup", `[]`)})
+			Expect(err).To(MatchError(ContainSubstring(`kind "OperatorGroup" cannot be patched`)))
+		})
+		It("should return an error for an invalid patch", func() {
+			err := ValidatePatches([]ResourcePatch{newPatch("Subscription", `{"op": "add"}`)})
+			Expect(err).To(MatchError(ContainSubstring("invalid JSON patch")))
+		})
+	})
+
+	Describe("patchCatalogSource", func() {
+		It("should apply only CatalogSource patches", func() {
+			cs := newCatalogSource("fakeName", "fakeNS", withSDKPublisher("fakeDisplay"))
+			Expect(patchCatalogSource(cs, []ResourcePatch{
+				newPatch("CatalogSource", `[{"op": "add", "path": "/spec/priority", "value": 10}]`),
+				newPatch("Subscription", `[{"op": "add", "path": "/spec/priority", "value": 20}]`),
+			})).To(Succeed())
+			Expect(cs.Spec.Priority).To(Equal(10))
+			Expect(cs.Spec.DisplayName).To(Equal("fakeDisplay"))
+		})
+		It("should return an error if a patch does not apply", func() {
+			cs := newCatalogSource("fakeName", "fakeNS")
+			err := patchCatalogSource(cs, []ResourcePatch{
+				newPatch("CatalogSource", `[{"op": "remove", "path": "/spec/grpcPodConfig"}]`),
+			})
+			Expect(err).To(MatchError(ContainSubstring("patch 0: apply to CatalogSource")))
+		})
+		It("should return an error if a patch changes a generated field", func() {
+			cs := newCatalogSource("fakeName", "fakeNS")
+			err := patchCatalogSource(cs, []ResourcePatch{
+				newPatch("CatalogSource", `[{"op": "add", "path": "/spec/image", "value": "quay.io/example/index:latest"}]`),
+			})
+			Expect(err).To(MatchError(`patches for CatalogSource "fakeName" must not change spec.image`))
+		})
+	})
+
+	Describe("patchSubscription", func() {
+		var sub *v1alpha1.Subscription
+		BeforeEach(func() {
+			sub = newSubscription("fakeName", "fakeNS",
+				withPackageChannel("fakePackage", "fakeChannel", "fakeCSV"),
+				withCatalogSource("fakeCatalog", "fakeNS"),
+				withInstallPlanApproval(v1alpha1.ApprovalManual))
+		})
+
+		It("should apply Subscription patches", func() {
+			Expect(patchSubscription(sub, []ResourcePatch{
+				newPatch("Subscription", `[{"op": "add", "path": "/spec/config", "value": {"env": [{"name": "FOO", "value": "bar"}]}}]`),
+			})).To(Succeed())
+			Expect(sub.Spec.Config.Env).To(HaveLen(1))
+			Expect(sub.Spec.Config.Env[0].Name).To(Equal("FOO"))
+		})
+		It("should return an error if a patch removes a required field", func() {
+			err := patchSubscription(sub, []ResourcePatch{
+				newPatch("Subscription", `[{"op": "remove", "path": "/spec/source"}]`),
+			})
+			Expect(err).To(MatchError(`patches for Subscription "fakename-sub" must not change spec.source`))
+		})
+		It("should return an error if a patch changes install plan approval", func() {
+			err := patchSubscription(sub, []ResourcePatch{
+				newPatch("Subscription", `[{"op": "replace", "path": "/spec/installPlanApproval", "value": "Automatic"}]`),
+			})
+			Expect(err).To(MatchError(ContainSubstring("must not change spec.installPlanApproval")))
+		})
+	})
+})
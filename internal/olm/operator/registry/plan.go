@@ -0,0 +1,220 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+	"github.com/operator-framework/operator-sdk/internal/olm/operator/registry/index"
+)
+
+// Actions an install would take on a PlannedResource.
+const (
+	PlanActionCreate = "create"
+	PlanActionReuse  = "reuse"
+	// PlanActionConflict means the resource already exists and the install will fail to create it.
+	PlanActionConflict = "conflict"
+)
+
+// PlannedResource is a cluster resource an install would create or reuse.
+type PlannedResource struct {
+	Kind string
+	Name string
+	// ClusterScoped is true if the resource is not in the install namespace.
+	ClusterScoped bool
+	Action        string
+	Details       []string
+}
+
+// PlanNamespaces returns the namespaces in names EnsureNamespace would create with Pod Security level,
+// or reuse if they exist.
+func PlanNamespaces(ctx context.Context, c client.Client, names []string, level string) ([]PlannedResource, error) {
+	var resources []PlannedResource
+	for _, name := range names {
+		ns := operator.NewNamespace(name, level)
+		action, err := planCreateAction(ctx, c, "", name, &corev1.Namespace{})
+		if err != nil {
+			return nil, err
+		}
+		if action == PlanActionConflict {
+			action = PlanActionReuse
+		}
+		resources = append(resources, PlannedResource{
+			Kind:          "Namespace",
+			Name:          ns.GetName(),
+			ClusterScoped: true,
+			Action:        action,
+			Details:       []string{"labels: " + describeMap(ns.GetLabels())},
+		})
+	}
+	return resources, nil
+}
+
+// PlanCatalog returns the resources CreateCatalog would create for a catalog named name.
+func (c IndexImageCatalogCreator) PlanCatalog(ctx context.Context, name string) ([]PlannedResource, error) {
+	cs, err := c.buildCatalogSource(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	action, err := planCreateAction(ctx, c.cfg.Client, cs.GetNamespace(), cs.GetName(), &v1alpha1.CatalogSource{})
+	if err != nil {
+		return nil, err
+	}
+	catalog := PlannedResource{
+		Kind:    v1alpha1.CatalogSourceKind,
+		Name:    cs.GetName(),
+		Action:  action,
+		Details: []string{"sourceType: " + string(v1alpha1.SourceTypeGrpc)},
+	}
+	if cs.Spec.DisplayName != "" {
+		catalog.Details = append(catalog.Details, "displayName: "+cs.Spec.DisplayName)
+	}
+	if cs.Spec.Publisher != "" {
+		catalog.Details = append(catalog.Details, "publisher: "+cs.Spec.Publisher)
+	}
+	if cs.Spec.Priority != 0 {
+		catalog.Details = append(catalog.Details, fmt.Sprintf("priority: %d", cs.Spec.Priority))
+	}
+	if len(cs.Spec.Secrets) != 0 {
+		catalog.Details = append(catalog.Details, "secrets: "+strings.Join(cs.Spec.Secrets, ", "))
+	}
+	if len(cs.GetLabels()) != 0 {
+		catalog.Details = append(catalog.Details, "labels: "+describeMap(cs.GetLabels()))
+	}
+	if len(cs.GetAnnotations()) != 0 {
+		catalog.Details = append(catalog.Details, "annotations: "+describeMap(cs.GetAnnotations()))
+	}
+	if c.InheritCatalogConfig != "" {
+		catalog.Details = append(catalog.Details, "inherits config from: "+c.InheritCatalogConfig)
+	}
+
+	c.setAddMode()
+	indexImage := c.IndexImage
+	if indexImage == "" {
+		indexImage = DefaultIndexImage
+	}
+	bundles := append(append([]string{}, c.AdditionalBundleImages...), c.BundleImage)
+	pod := PlannedResource{
+		Kind:   "Pod",
+		Name:   index.GetPodName(c.BundleImage),
+		Action: PlanActionCreate,
+		Details: []string{
+			"indexImage: " + indexImage,
+			"bundles: " + strings.Join(bundles, ", "),
+			"bundleAddMode: " + string(c.BundleAddMode),
+		},
+	}
+	return []PlannedResource{catalog, pod}, nil
+}
+
+// PlanInstall returns the OperatorGroup and Subscription InstallOperator would create or reuse.
+func (o OperatorInstaller) PlanInstall(ctx context.Context) ([]PlannedResource, error) {
+	og, ogFound, err := o.getOperatorGroup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targetNamespaces, err := o.resolveTargetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	ogPlan := PlannedResource{Kind: v1.OperatorGroupKind}
+	if ogFound {
+		if err := o.isOperatorGroupCompatible(*og, targetNamespaces); err != nil {
+			return nil, err
+		}
+		ogPlan.Name, ogPlan.Action = og.GetName(), PlanActionReuse
+		targetNamespaces = og.Spec.TargetNamespaces
+	} else {
+		og = newSDKOperatorGroup(o.cfg.Namespace, withTargetNamespaces(targetNamespaces...))
+		ogPlan.Name, ogPlan.Action = og.GetName(), PlanActionCreate
+	}
+	ogPlan.Details = []string{"targets: " + describeTargetNamespaces(targetNamespaces)}
+
+	sub, err := o.buildSubscription(o.CatalogSourceName)
+	if err != nil {
+		return nil, err
+	}
+	action, err := planCreateAction(ctx, o.cfg.Client, sub.GetNamespace(), sub.GetName(), &v1alpha1.Subscription{})
+	if err != nil {
+		return nil, err
+	}
+	subPlan := PlannedResource{
+		Kind:   v1alpha1.SubscriptionKind,
+		Name:   sub.GetName(),
+		Action: action,
+		Details: []string{
+			"package: " + sub.Spec.Package,
+			"channel: " + sub.Spec.Channel,
+			"startingCSV: " + sub.Spec.StartingCSV,
+			"source: " + sub.Spec.CatalogSource,
+		},
+	}
+
+	return []PlannedResource{ogPlan, subPlan}, nil
+}
+
+// describeMap returns m's entries as comma-separated key=value pairs, sorted by key.
+func describeMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// planCreateAction returns PlanActionConflict if an object named name exists, otherwise PlanActionCreate.
+func planCreateAction(ctx context.Context, c client.Client, namespace, name string, obj client.Object) (string, error) {
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	switch {
+	case err == nil:
+		return PlanActionConflict, nil
+	case apierrors.IsNotFound(err):
+		return PlanActionCreate, nil
+	default:
+		return "", fmt.Errorf("error getting %s: %v", name, err)
+	}
+}
+
+// WritePlan writes a human-readable description of resources to w.
+func WritePlan(w io.Writer, namespace string, resources []PlannedResource) error {
+	for _, r := range resources {
+		name := namespace + "/" + r.Name
+		if r.ClusterScoped {
+			name = r.Name
+		}
+		if _, err := fmt.Fprintf(w, "%s %s: %s\n", r.Kind, name, r.Action); err != nil {
+			return err
+		}
+		for _, detail := range r.Details {
+			if _, err := fmt.Fprintf(w, "  %s\n", detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
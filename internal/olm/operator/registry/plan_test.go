@@ -0,0 +1,174 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/olm/operator"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		cfg    *operator.Configuration
+		client crclient.Client
+	)
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(v1.AddToScheme(sch)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		Expect(corev1.AddToScheme(sch)).To(Succeed())
+		client = fake.NewClientBuilder().WithScheme(sch).Build()
+		cfg = &operator.Configuration{Scheme: sch, Client: client, Namespace: "testns"}
+	})
+
+	Describe("PlanCatalog", func() {
+		var c *IndexImageCatalogCreator
+		BeforeEach(func() {
+			c = NewIndexImageCatalogCreator(cfg)
+			c.BundleImage = "quay.io/example/memcached-operator-bundle:v0.0.1"
+			c.IndexImage = DefaultIndexImage
+		})
+
+		It("should plan a catalog source and registry pod", func() {
+			resources, err := c.PlanCatalog(context.TODO(), "memcached-operator-catalog")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources).To(HaveLen(2))
+			Expect(resources[0].Kind).To(Equal(v1alpha1.CatalogSourceKind))
+			Expect(resources[0].Action).To(Equal(PlanActionCreate))
+			Expect(resources[1].Kind).To(Equal("Pod"))
+			Expect(resources[1].Details).To(ContainElement("indexImage: " + DefaultIndexImage))
+			Expect(resources[1].Details).To(ContainElement("bundleAddMode: semver"))
+		})
+		It("should plan the catalog source CreateCatalog would create", func() {
+			c.Labels = map[string]string{"team": "a"}
+			c.Annotations = map[string]string{"owner": "a@example.com"}
+			c.CatalogSourceTemplate = &v1alpha1.CatalogSource{Spec: v1alpha1.CatalogSourceSpec{DisplayName: "Templated"}}
+			c.Patches = []ResourcePatch{
+				{Kind: v1alpha1.CatalogSourceKind, Patch: json.RawMessage(`[{"op": "add", "path": "/spec/priority", "value": 10}]`)},
+			}
+			resources, err := c.PlanCatalog(context.TODO(), "memcached-operator-catalog")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources[0].Details).To(ContainElements(
+				"displayName: Templated",
+				"priority: 10",
+				"labels: operators.operatorframework.io/created-by=operator-sdk, team=a",
+				"annotations: owner=a@example.com",
+			))
+		})
+		It("should report a conflict if the catalog source exists", func() {
+			Expect(client.Create(context.TODO(), newCatalogSource("memcached-operator-catalog", "testns"))).To(Succeed())
+			resources, err := c.PlanCatalog(context.TODO(), "memcached-operator-catalog")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources[0].Action).To(Equal(PlanActionConflict))
+		})
+	})
+
+	Describe("PlanInstall", func() {
+		var oi *OperatorInstaller
+		BeforeEach(func() {
+			oi = NewOperatorInstaller(cfg)
+			oi.PackageName = "memcached-operator"
+			oi.Channel = "alpha"
+			oi.StartingCSV = "memcached-operator.v0.0.1"
+			oi.CatalogSourceName = "memcached-operator-catalog"
+			oi.SupportedInstallModes = operator.GetSupportedInstallModes([]v1alpha1.InstallMode{
+				{Type: v1alpha1.InstallModeTypeAllNamespaces, Supported: true},
+			})
+		})
+
+		It("should plan an OperatorGroup and Subscription", func() {
+			resources, err := oi.PlanInstall(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources).To(Equal([]PlannedResource{
+				{
+					Kind:    v1.OperatorGroupKind,
+					Name:    operator.SDKOperatorGroupName,
+					Action:  PlanActionCreate,
+					Details: []string{"targets: all namespaces"},
+				},
+				{
+					Kind:   v1alpha1.SubscriptionKind,
+					Name:   "memcached-operator-v0-0-1-sub",
+					Action: PlanActionCreate,
+					Details: []string{
+						"package: memcached-operator",
+						"channel: alpha",
+						"startingCSV: memcached-operator.v0.0.1",
+						"source: memcached-operator-catalog",
+					},
+				},
+			}))
+		})
+		It("should plan the patched Subscription", func() {
+			oi.Patches = []ResourcePatch{
+				{Kind: v1alpha1.SubscriptionKind, Patch: json.RawMessage(`[{"op": "replace", "path": "/spec/channel", "value": "beta"}]`)},
+			}
+			resources, err := oi.PlanInstall(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources[1].Details).To(ContainElement("channel: beta"))
+		})
+		It("should reuse an existing OperatorGroup", func() {
+			_ = createOperatorGroupHelper(context.TODO(), client, "existing-og", "testns")
+			resources, err := oi.PlanInstall(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources[0].Name).To(Equal("existing-og"))
+			Expect(resources[0].Action).To(Equal(PlanActionReuse))
+		})
+	})
+
+	Describe("PlanNamespaces", func() {
+		It("should plan namespaces to create and reuse", func() {
+			Expect(client.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "existing"}})).To(Succeed())
+			resources, err := PlanNamespaces(context.TODO(), client, []string{"testns", "existing"}, "baseline")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resources).To(HaveLen(2))
+			Expect(resources[0].Kind).To(Equal("Namespace"))
+			Expect(resources[0].ClusterScoped).To(BeTrue())
+			Expect(resources[0].Action).To(Equal(PlanActionCreate))
+			Expect(resources[0].Details).To(ConsistOf(ContainSubstring("pod-security.kubernetes.io/enforce=baseline")))
+			Expect(resources[1].Action).To(Equal(PlanActionReuse))
+		})
+	})
+
+	Describe("WritePlan", func() {
+		It("should write each resource and its details", func() {
+			buf := &bytes.Buffer{}
+			Expect(WritePlan(buf, "testns", []PlannedResource{
+				{Kind: "OperatorGroup", Name: "operator-sdk-og", Action: PlanActionCreate, Details: []string{"targets: all namespaces"}},
+			})).To(Succeed())
+			Expect(buf.String()).To(Equal("OperatorGroup testns/operator-sdk-og: create\n  targets: all namespaces\n"))
+		})
+		It("should not prefix cluster-scoped resources with the namespace", func() {
+			buf := &bytes.Buffer{}
+			Expect(WritePlan(buf, "testns", []PlannedResource{
+				{Kind: "Namespace", Name: "testns", ClusterScoped: true, Action: PlanActionCreate},
+			})).To(Succeed())
+			Expect(buf.String()).To(Equal("Namespace testns: create\n"))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// createWithRetry creates obj, retrying with client-go's default backoff while the API server
+// returns conflict or transient errors. The last error is returned if retries are exhausted.
+// If an attempt fails with an error after which obj may have been created anyway, ex. a timeout,
+// and the retry finds that obj already exists, obj is read from the cluster and nil is returned.
+func createWithRetry(ctx context.Context, c client.Client, obj client.Object) error {
+	mayExist := false
+	return retry.OnError(retry.DefaultBackoff, isRetryableError, func() error {
+		err := c.Create(ctx, obj)
+		if mayExist && apierrors.IsAlreadyExists(err) {
+			return c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		}
+		mayExist = isAmbiguousError(err)
+		return err
+	})
+}
+
+// isRetryableError returns true if err is a conflict or an error the API server
+// returns under contention, which may succeed if the request is retried.
+func isRetryableError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		isAmbiguousError(err)
+}
+
+// isAmbiguousError returns true if err does not show whether the request was applied.
+func isAmbiguousError(err error) bool {
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err)
+}
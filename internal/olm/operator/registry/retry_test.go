@@ -0,0 +1,93 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// failingCreateClient returns errs from its first len(errs) Create calls.
+type failingCreateClient struct {
+	client.Client
+	errs  []error
+	calls int
+}
+
+func (c *failingCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return c.errs[c.calls-1]
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("createWithRetry", func() {
+	var c *failingCreateClient
+	gr := schema.GroupResource{Group: "operators.coreos.com", Resource: "subscriptions"}
+
+	BeforeEach(func() {
+		sch := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		c = &failingCreateClient{Client: fake.NewClientBuilder().WithScheme(sch).Build()}
+	})
+
+	It("should retry conflicts and transient errors", func() {
+		c.errs = []error{
+			apierrors.NewConflict(gr, "fakeName", nil),
+			apierrors.NewServiceUnavailable("etcd leader changed"),
+		}
+		sub := newSubscription("fakeName", "fakeNS")
+		Expect(createWithRetry(context.TODO(), c, sub)).To(Succeed())
+		Expect(c.calls).To(Equal(3))
+	})
+	It("should not retry other errors", func() {
+		c.errs = []error{apierrors.NewForbidden(gr, "fakeName", nil)}
+		err := createWithRetry(context.TODO(), c, newSubscription("fakeName", "fakeNS"))
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(c.calls).To(Equal(1))
+	})
+	It("should succeed if a retry after a timeout finds the object already created", func() {
+		Expect(c.Client.Create(context.TODO(), newSubscription("fakeName", "fakeNS"))).To(Succeed())
+		c.errs = []error{apierrors.NewTimeoutError("request timed out", 0)}
+		sub := newSubscription("fakeName", "fakeNS")
+		Expect(createWithRetry(context.TODO(), c, sub)).To(Succeed())
+		Expect(c.calls).To(Equal(2))
+		Expect(sub.GetResourceVersion()).ToNot(BeEmpty())
+	})
+	It("should return AlreadyExists if no earlier attempt may have created the object", func() {
+		Expect(c.Client.Create(context.TODO(), newSubscription("fakeName", "fakeNS"))).To(Succeed())
+		c.errs = []error{apierrors.NewConflict(gr, "fakeName", nil)}
+		err := createWithRetry(context.TODO(), c, newSubscription("fakeName", "fakeNS"))
+		Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+		Expect(c.calls).To(Equal(2))
+	})
+	It("should return the last error if retries are exhausted", func() {
+		for i := 0; i < 10; i++ {
+			c.errs = append(c.errs, apierrors.NewTooManyRequests("slow down", 0))
+		}
+		err := createWithRetry(context.TODO(), c, newSubscription("fakeName", "fakeNS"))
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+		Expect(c.calls).To(BeNumerically("<", 10))
+	})
+})
@@ -0,0 +1,83 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StageTiming is the duration of a named install stage, and its error if it failed.
+type StageTiming struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// StageTimer records how long each install stage takes. Durations are logged
+// at info level if Log is true, otherwise at debug level.
+// A nil *StageTimer records nothing.
+type StageTimer struct {
+	Log    bool
+	Stages []StageTiming
+	// Logger, if set, is used instead of the standard logger.
+	Logger *log.Entry
+}
+
+// Track starts timing stage and a tracing span for it, and returns a context holding the span,
+// so stages started with it are children of stage, and a function that ends the span and records
+// the stage's duration. The function must be called on every return path with the stage's error,
+// if any, which is recorded on both the span and the stage.
+func (t *StageTimer) Track(ctx context.Context, stage string) (context.Context, func(error)) {
+	ctx, span := StartSpan(ctx, stage)
+	start := time.Now()
+	return ctx, func(err error) {
+		EndSpan(span, err)
+		if t == nil {
+			return
+		}
+		d := time.Since(start)
+		timing := StageTiming{Name: stage, Duration: d}
+		if err != nil {
+			timing.Error = err.Error()
+		}
+		t.Stages = append(t.Stages, timing)
+		logf := LoggerOrStandard(t.Logger).Debugf
+		if t.Log {
+			logf = LoggerOrStandard(t.Logger).Infof
+		}
+		if err != nil {
+			logf("Stage %q failed after %s", stage, d.Round(time.Millisecond))
+		} else {
+			logf("Stage %q took %s", stage, d.Round(time.Millisecond))
+		}
+	}
+}
+
+// FailedStage returns the first recorded stage that failed, which is the innermost one
+// if nested stages failed with the same error, or an empty string if none failed.
+func (t *StageTimer) FailedStage() string {
+	if t == nil {
+		return ""
+	}
+	for _, s := range t.Stages {
+		if s.Error != "" {
+			return s.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("StageTimer", func() {
+	It("should record stages in the order they complete", func() {
+		t := &StageTimer{}
+		ctx, doneOuter := t.Track(context.TODO(), "outer")
+		_, doneInner := t.Track(ctx, "inner")
+		doneInner(nil)
+		doneOuter(nil)
+
+		Expect(t.Stages).To(HaveLen(2))
+		Expect(t.Stages[0].Name).To(Equal("inner"))
+		Expect(t.Stages[1].Name).To(Equal("outer"))
+		Expect(t.Stages[1].Duration).To(BeNumerically(">=", t.Stages[0].Duration))
+		Expect(t.FailedStage()).To(BeEmpty())
+	})
+	It("should record a failed stage and report the innermost one", func() {
+		t := &StageTimer{}
+		err := errors.New("timed out")
+		ctx, doneOuter := t.Track(context.TODO(), "outer")
+		_, doneInner := t.Track(ctx, "inner")
+		doneInner(err)
+		doneOuter(err)
+
+		Expect(t.Stages).To(HaveLen(2))
+		Expect(t.Stages[0].Error).To(Equal("timed out"))
+		Expect(t.Stages[1].Error).To(Equal("timed out"))
+		Expect(t.FailedStage()).To(Equal("inner"))
+	})
+	It("should start a stage's span as a child of the span in ctx", func() {
+		exporter := tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		// The default global provider delegates to the first provider set, so restore a no-op one.
+		defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTracerProvider(tp)
+
+		t := &StageTimer{}
+		ctx, doneOuter := t.Track(context.TODO(), "outer")
+		_, doneInner := t.Track(ctx, "inner")
+		doneInner(errors.New("failed"))
+		doneOuter(nil)
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name).To(Equal("inner"))
+		Expect(spans[0].Parent.SpanID()).To(Equal(spans[1].SpanContext.SpanID()))
+		Expect(spans[0].StatusCode).To(Equal(codes.Error))
+		Expect(spans[1].StatusCode).To(Equal(codes.Unset))
+	})
+	It("should do nothing if nil", func() {
+		var t *StageTimer
+		Expect(func() {
+			_, done := t.Track(context.TODO(), "stage")
+			done(errors.New("failed"))
+		}).ToNot(Panic())
+		Expect(t.FailedStage()).To(BeEmpty())
+	})
+})
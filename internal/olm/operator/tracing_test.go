@@ -0,0 +1,44 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracing", func() {
+	Describe("SetupTracing", func() {
+		It("should be a no-op if no OTLP endpoint is configured", func() {
+			for _, key := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+				if val, ok := os.LookupEnv(key); ok {
+					Expect(os.Unsetenv(key)).To(Succeed())
+					defer os.Setenv(key, val)
+				}
+			}
+
+			shutdown, err := SetupTracing(context.TODO())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shutdown(context.TODO())).To(Succeed())
+
+			_, span := StartSpan(context.TODO(), "stage")
+			Expect(span.IsRecording()).To(BeFalse())
+			span.End()
+		})
+	})
+})
@@ -22,7 +22,6 @@ import (
 
 	v1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -52,6 +51,7 @@ type Uninstall struct {
 func NewUninstall(cfg *Configuration) *Uninstall {
 	return &Uninstall{
 		config: cfg,
+		Logf:   NewRunLogger().Infof,
 	}
 }
 
@@ -131,8 +131,14 @@ func (u *Uninstall) Run(ctx context.Context) error {
 	}
 
 	// Get the catalog source to make sure the correct error is returned.
+	// A catalog source not created by operator-sdk, ex. an existing catalog installed from
+	// with "run package", may serve other operators.
 	if err := u.config.Client.Get(ctx, catsrcKey, catsrc); err == nil {
-		csObj = catsrc
+		if IsSDKCatalogSource(catsrc) {
+			csObj = catsrc
+		} else {
+			u.Logf("Skipping deletion of CatalogSource %q not created by operator-sdk", catsrcKey)
+		}
 	} else if !apierrors.IsNotFound(err) {
 		return fmt.Errorf("error get catalog source: %v", err)
 	}
@@ -155,7 +161,7 @@ func (u *Uninstall) Run(ctx context.Context) error {
 	if u.DeleteCRDs {
 		objs = append(objs, crds...)
 	} else {
-		log.Info("Skipping CRD deletion")
+		u.Logf("Skipping CRD deletion")
 
 	}
 
@@ -172,7 +178,7 @@ func (u *Uninstall) Run(ctx context.Context) error {
 			return err
 		}
 	} else {
-		log.Info("Skipping Operator Groups deletion")
+		u.Logf("Skipping Operator Groups deletion")
 	}
 
 	// If no objects were cleaned up, the package was not found.
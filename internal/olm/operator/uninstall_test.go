@@ -0,0 +1,106 @@
+// Copyright 2022 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Uninstall", func() {
+	var (
+		u    *Uninstall
+		c    client.Client
+		logs []string
+	)
+
+	newSubscription := func(catalogName string) *v1alpha1.Subscription {
+		return &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "my.pkg-sub"},
+			Spec: &v1alpha1.SubscriptionSpec{
+				Package:                "my.pkg",
+				CatalogSource:          catalogName,
+				CatalogSourceNamespace: "testns",
+			},
+		}
+	}
+	newCatalogSource := func(name, publisher string, annotations map[string]string) *v1alpha1.CatalogSource {
+		return &v1alpha1.CatalogSource{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: name, Annotations: annotations},
+			Spec:       v1alpha1.CatalogSourceSpec{Publisher: publisher},
+		}
+	}
+	setup := func(objs ...client.Object) {
+		sch := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(sch).WithObjects(objs...).Build()
+		u = NewUninstall(&Configuration{Namespace: "testns", Client: c, Scheme: sch})
+		u.Package = "my.pkg"
+		u.DeleteAll = false
+		logs = nil
+		u.Logf = func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	}
+	catalogExists := func(name string) bool {
+		err := c.Get(context.TODO(), client.ObjectKey{Namespace: "testns", Name: name}, &v1alpha1.CatalogSource{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	It("should delete an SDK catalog not named for the package", func() {
+		cs := newCatalogSource("my-pkg-catalog", "my-team",
+			map[string]string{IndexImageAnnotation: "quay.io/operator-framework/opm:latest"})
+		setup(newSubscription(cs.GetName()), cs)
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(catalogExists(cs.GetName())).To(BeFalse())
+	})
+	It("should delete an SDK catalog identified by its publisher", func() {
+		cs := newCatalogSource("my.pkg-catalog", SDKCatalogSourcePublisher, nil)
+		setup(newSubscription(cs.GetName()), cs)
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(catalogExists(cs.GetName())).To(BeFalse())
+	})
+	It("should delete an SDK catalog with a custom publisher whose registry pod failed", func() {
+		cs := newCatalogSource("my.pkg-catalog", "My Team", nil)
+		cs.SetLabels(map[string]string{SDKCatalogSourceLabel: SDKCatalogSourceLabelValue})
+		setup(newSubscription(cs.GetName()), cs)
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(catalogExists(cs.GetName())).To(BeFalse())
+	})
+	It("should not delete a catalog not created by operator-sdk", func() {
+		cs := newCatalogSource("community-operators", "Red Hat", nil)
+		setup(newSubscription(cs.GetName()), cs)
+		Expect(u.Run(context.TODO())).To(Succeed())
+		Expect(catalogExists(cs.GetName())).To(BeTrue())
+		Expect(logs).To(ContainElement(ContainSubstring("Skipping deletion of CatalogSource")))
+	})
+	It("should not delete a foreign catalog named for the package", func() {
+		cs := newCatalogSource("my.pkg-catalog", "Red Hat", nil)
+		setup(cs)
+		Expect(u.Run(context.TODO())).To(MatchError(&ErrPackageNotFound{"my.pkg"}))
+		Expect(catalogExists(cs.GetName())).To(BeTrue())
+	})
+})
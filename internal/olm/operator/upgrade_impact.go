@@ -0,0 +1,185 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CSVImpact summarizes the API and RBAC changes between an installed CSV and the CSV replacing it.
+type CSVImpact struct {
+	From, To string
+
+	AddedCRDs, RemovedCRDs []string
+	// ChangedCRDVersions describes each owned CRD whose owned versions changed, ex. "foos.example.com: v1alpha1 -> v1alpha1, v1".
+	ChangedCRDVersions []string
+
+	AddedAPIServices, RemovedAPIServices []string
+
+	AddedRules, RemovedRules []string
+}
+
+// IsDestructive returns true if the impact removes owned CRDs, whose CRs would no longer be managed.
+func (i CSVImpact) IsDestructive() bool {
+	return len(i.RemovedCRDs) != 0
+}
+
+// CompareCSVs returns the impact of replacing installed with csv.
+func CompareCSVs(installed, csv *v1alpha1.ClusterServiceVersion) CSVImpact {
+	impact := CSVImpact{From: installed.GetName(), To: csv.GetName()}
+
+	oldCRDs, newCRDs := getOwnedCRDVersions(installed), getOwnedCRDVersions(csv)
+	impact.AddedCRDs, impact.RemovedCRDs = diffKeys(oldCRDs, newCRDs)
+	for _, name := range sets.StringKeySet(oldCRDs).Intersection(sets.StringKeySet(newCRDs)).List() {
+		if !oldCRDs[name].Equal(newCRDs[name]) {
+			impact.ChangedCRDVersions = append(impact.ChangedCRDVersions, fmt.Sprintf("%s: %s -> %s",
+				name, strings.Join(oldCRDs[name].List(), ", "), strings.Join(newCRDs[name].List(), ", ")))
+		}
+	}
+
+	impact.AddedAPIServices, impact.RemovedAPIServices = diffSets(getOwnedAPIServices(installed), getOwnedAPIServices(csv))
+	impact.AddedRules, impact.RemovedRules = diffSets(getRules(installed), getRules(csv))
+	return impact
+}
+
+// Write writes a summary of the impact to w.
+func (i CSVImpact) Write(w io.Writer) {
+	fmt.Fprintf(w, "Changes from installed CSV %q to %q:\n", i.From, i.To)
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Removed owned CRDs", i.RemovedCRDs},
+		{"Added owned CRDs", i.AddedCRDs},
+		{"Changed owned CRD versions", i.ChangedCRDVersions},
+		{"Removed owned APIServices", i.RemovedAPIServices},
+		{"Added owned APIServices", i.AddedAPIServices},
+		{"Removed RBAC rules", i.RemovedRules},
+		{"Added RBAC rules", i.AddedRules},
+	}
+	written := false
+	for _, s := range sections {
+		if len(s.items) == 0 {
+			continue
+		}
+		written = true
+		fmt.Fprintf(w, "  %s:\n", s.title)
+		for _, item := range s.items {
+			fmt.Fprintf(w, "    %s\n", item)
+		}
+	}
+	if !written {
+		fmt.Fprintln(w, "  No API or RBAC changes")
+	}
+}
+
+// GetInstalledPackageCSV returns the CSV installed by the Subscription to pkg in namespace,
+// or nil if there is no Subscription to pkg or it has not installed a CSV.
+func GetInstalledPackageCSV(ctx context.Context, c client.Reader, namespace, pkg string) (*v1alpha1.ClusterServiceVersion, error) {
+	subs := v1alpha1.SubscriptionList{}
+	if err := c.List(ctx, &subs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list subscriptions: %v", err)
+	}
+	for _, sub := range subs.Items {
+		if sub.Spec == nil || sub.Spec.Package != pkg || sub.Status.InstalledCSV == "" {
+			continue
+		}
+		csv := &v1alpha1.ClusterServiceVersion{}
+		key := client.ObjectKey{Namespace: namespace, Name: sub.Status.InstalledCSV}
+		if err := c.Get(ctx, key, csv); err != nil {
+			return nil, fmt.Errorf("get installed CSV %q: %v", key.Name, err)
+		}
+		return csv, nil
+	}
+	return nil, nil
+}
+
+// CheckUpgradeImpact writes the impact of replacing the CSV installed for pkg in namespace with csv to w.
+// An error is returned if the impact is destructive and confirm is false.
+func CheckUpgradeImpact(ctx context.Context, c client.Reader, w io.Writer, namespace, pkg string,
+	csv *v1alpha1.ClusterServiceVersion, confirm bool) error {
+
+	installed, err := GetInstalledPackageCSV(ctx, c, namespace, pkg)
+	if err != nil {
+		return err
+	}
+	if installed == nil {
+		fmt.Fprintf(w, "No CSV is installed for package %q in namespace %q\n", pkg, namespace)
+		return nil
+	}
+	impact := CompareCSVs(installed, csv)
+	impact.Write(w)
+	if impact.IsDestructive() && !confirm {
+		return fmt.Errorf("CSV %q removes owned CRDs %+q; set --confirm to install it anyway", csv.GetName(), impact.RemovedCRDs)
+	}
+	return nil
+}
+
+func getOwnedCRDVersions(csv *v1alpha1.ClusterServiceVersion) map[string]sets.String {
+	crds := map[string]sets.String{}
+	for _, desc := range csv.Spec.CustomResourceDefinitions.Owned {
+		if _, ok := crds[desc.Name]; !ok {
+			crds[desc.Name] = sets.NewString()
+		}
+		crds[desc.Name].Insert(desc.Version)
+	}
+	return crds
+}
+
+func getOwnedAPIServices(csv *v1alpha1.ClusterServiceVersion) sets.String {
+	apis := sets.NewString()
+	for _, desc := range csv.Spec.APIServiceDefinitions.Owned {
+		apis.Insert(fmt.Sprintf("%s.%s.%s", desc.Version, desc.Group, desc.Kind))
+	}
+	return apis
+}
+
+// getRules returns csv's permission and cluster permission rules, each formatted on one line.
+func getRules(csv *v1alpha1.ClusterServiceVersion) sets.String {
+	rules := sets.NewString()
+	spec := csv.Spec.InstallStrategy.StrategySpec
+	for _, perms := range []struct {
+		scope string
+		perms []v1alpha1.StrategyDeploymentPermissions
+	}{
+		{"namespace", spec.Permissions},
+		{"cluster", spec.ClusterPermissions},
+	} {
+		for _, p := range perms.perms {
+			for _, r := range p.Rules {
+				rules.Insert(fmt.Sprintf("%s (%s): apiGroups=%q resources=%q resourceNames=%q nonResourceURLs=%q verbs=%q",
+					p.ServiceAccountName, perms.scope, r.APIGroups, r.Resources, r.ResourceNames, r.NonResourceURLs, r.Verbs))
+			}
+		}
+	}
+	return rules
+}
+
+// diffKeys returns the sorted keys in after but not before, and in before but not after.
+func diffKeys(before, after map[string]sets.String) (added, removed []string) {
+	return diffSets(sets.StringKeySet(before), sets.StringKeySet(after))
+}
+
+// diffSets returns the sorted items in after but not before, and in before but not after.
+func diffSets(before, after sets.String) (added, removed []string) {
+	return after.Difference(before).List(), before.Difference(after).List()
+}
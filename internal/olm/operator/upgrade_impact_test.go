@@ -0,0 +1,110 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Upgrade impact", func() {
+	newCSV := func(name string, crds []v1alpha1.CRDDescription, rules ...rbacv1.PolicyRule) *v1alpha1.ClusterServiceVersion {
+		csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "testns"}}
+		csv.Spec.CustomResourceDefinitions.Owned = crds
+		csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions = []v1alpha1.StrategyDeploymentPermissions{
+			{ServiceAccountName: "memcached-operator", Rules: rules},
+		}
+		return csv
+	}
+	memcachedRule := rbacv1.PolicyRule{APIGroups: []string{"cache.example.com"}, Resources: []string{"memcacheds"}, Verbs: []string{"get"}}
+	podsRule := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}}
+
+	installed := newCSV("memcached-operator.v0.0.1", []v1alpha1.CRDDescription{
+		{Name: "memcacheds.cache.example.com", Version: "v1alpha1"},
+		{Name: "backups.cache.example.com", Version: "v1alpha1"},
+	}, memcachedRule)
+
+	Describe("CompareCSVs", func() {
+		It("should report CRD, version, and RBAC changes", func() {
+			csv := newCSV("memcached-operator.v0.0.2", []v1alpha1.CRDDescription{
+				{Name: "memcacheds.cache.example.com", Version: "v1alpha1"},
+				{Name: "memcacheds.cache.example.com", Version: "v1"},
+				{Name: "restores.cache.example.com", Version: "v1"},
+			}, memcachedRule, podsRule)
+
+			impact := CompareCSVs(installed, csv)
+			Expect(impact.AddedCRDs).To(Equal([]string{"restores.cache.example.com"}))
+			Expect(impact.RemovedCRDs).To(Equal([]string{"backups.cache.example.com"}))
+			Expect(impact.ChangedCRDVersions).To(Equal([]string{"memcacheds.cache.example.com: v1alpha1 -> v1, v1alpha1"}))
+			Expect(impact.AddedRules).To(HaveLen(1))
+			Expect(impact.AddedRules[0]).To(ContainSubstring(`resources=["pods"]`))
+			Expect(impact.RemovedRules).To(BeEmpty())
+			Expect(impact.IsDestructive()).To(BeTrue())
+		})
+		It("should report no changes for equivalent CSVs", func() {
+			impact := CompareCSVs(installed, installed)
+			Expect(impact.IsDestructive()).To(BeFalse())
+			w := &bytes.Buffer{}
+			impact.Write(w)
+			Expect(w.String()).To(Equal("Changes from installed CSV \"memcached-operator.v0.0.1\" to \"memcached-operator.v0.0.1\":\n" +
+				"  No API or RBAC changes\n"))
+		})
+	})
+
+	Describe("CheckUpgradeImpact", func() {
+		var sch *runtime.Scheme
+		BeforeEach(func() {
+			sch = runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(sch)).To(Succeed())
+		})
+		newSub := func(pkg, installedCSV string) *v1alpha1.Subscription {
+			return &v1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: pkg + "-sub", Namespace: "testns"},
+				Spec:       &v1alpha1.SubscriptionSpec{Package: pkg},
+				Status:     v1alpha1.SubscriptionStatus{InstalledCSV: installedCSV},
+			}
+		}
+		csv := newCSV("memcached-operator.v0.0.2", []v1alpha1.CRDDescription{
+			{Name: "memcacheds.cache.example.com", Version: "v1alpha1"},
+		}, memcachedRule)
+
+		It("should report that nothing is installed", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).Build()
+			w := &bytes.Buffer{}
+			Expect(CheckUpgradeImpact(context.TODO(), c, w, "testns", "memcached-operator", csv, false)).To(Succeed())
+			Expect(w.String()).To(Equal("No CSV is installed for package \"memcached-operator\" in namespace \"testns\"\n"))
+		})
+		It("should require confirmation to remove owned CRDs", func() {
+			c := fake.NewClientBuilder().WithScheme(sch).WithObjects(
+				newSub("memcached-operator", installed.GetName()), installed.DeepCopy(),
+			).Build()
+			w := &bytes.Buffer{}
+			err := CheckUpgradeImpact(context.TODO(), c, w, "testns", "memcached-operator", csv, false)
+			Expect(err).To(MatchError(`CSV "memcached-operator.v0.0.2" removes owned CRDs ["backups.cache.example.com"]; ` +
+				`set --confirm to install it anyway`))
+			Expect(w.String()).To(ContainSubstring("  Removed owned CRDs:\n    backups.cache.example.com\n"))
+
+			Expect(CheckUpgradeImpact(context.TODO(), c, w, "testns", "memcached-operator", csv, true)).To(Succeed())
+		})
+	})
+})
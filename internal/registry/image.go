@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/docker/distribution/reference"
 	registryimage "github.com/operator-framework/operator-registry/pkg/image"
 	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
 	log "github.com/sirupsen/logrus"
@@ -112,3 +113,39 @@ func GetImageLabels(ctx context.Context, logger *log.Entry, image string, local
 
 	return labels, err
 }
+
+// CheckImageReachable resolves image's manifest in its remote registry without
+// pulling it, so registry connectivity and authorization problems can be
+// reported before any image is pulled.
+func CheckImageReachable(ctx context.Context, image string, skipTLSVerify bool, useHTTP bool) error {
+	resolver, err := containerdregistry.NewResolver("", skipTLSVerify, useHTTP, nil)
+	if err != nil {
+		return fmt.Errorf("error creating image resolver: %v", err)
+	}
+	if _, _, err := resolver.Resolve(ctx, image); err != nil {
+		return fmt.Errorf("cannot reach image %s: %v", image, err)
+	}
+	return nil
+}
+
+// ResolveImageDigest resolves image's manifest digest in its remote registry and
+// returns image's repository referenced by that digest, ex. to pin a tag.
+func ResolveImageDigest(ctx context.Context, image string, skipTLSVerify bool, useHTTP bool) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %v", image, err)
+	}
+	resolver, err := containerdregistry.NewResolver("", skipTLSVerify, useHTTP, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating image resolver: %v", err)
+	}
+	_, desc, err := resolver.Resolve(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve image %s digest: %v", image, err)
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), desc.Digest)
+	if err != nil {
+		return "", fmt.Errorf("error pinning image %s to digest %s: %v", image, desc.Digest, err)
+	}
+	return pinned.String(), nil
+}